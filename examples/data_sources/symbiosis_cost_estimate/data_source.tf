@@ -0,0 +1,17 @@
+data "symbiosis_cost_estimate" "example" {
+  ha_control_planes = 1
+
+  node_pool {
+    node_type = "general-int-1"
+    quantity  = 3
+  }
+
+  node_pool {
+    node_type = "general-int-2"
+    quantity  = 6
+  }
+}
+
+output "monthly_cost" {
+  value = "${data.symbiosis_cost_estimate.example.monthly_cost} ${data.symbiosis_cost_estimate.example.currency}"
+}
@@ -0,0 +1,5 @@
+data "symbiosis_team_quota" "quota" {}
+
+output "nodes_left" {
+  value = data.symbiosis_team_quota.quota.max_nodes - data.symbiosis_team_quota.quota.used_nodes
+}
@@ -0,0 +1,5 @@
+# Node pools can be imported by cluster and pool name
+terraform import symbiosis_node_pool.example my-production-cluster/example-pool
+
+# or by node pool ID
+terraform import symbiosis_node_pool.example 8ad8bd55-6fd1-4c9b-8bd3-4f2b5d4d42a8
@@ -7,7 +7,12 @@ import (
 	"github.com/symbiosis-cloud/terraform-provider-symbiosis/symbiosis"
 )
 
+// version is set by goreleaser through ldflags
+var version string = "dev"
+
 func main() {
+	symbiosis.ProviderVersion = version
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: func() *schema.Provider {
 			return symbiosis.Provider()
@@ -0,0 +1,261 @@
+package symbiosis
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+const (
+	// clusterPrefetchThreshold is the number of cluster cache misses after
+	// which all clusters are listed at once instead of described one by one.
+	clusterPrefetchThreshold = 3
+
+	clusterListPageSize = 100
+)
+
+// apiCache keeps describe, list and identity responses for the lifetime of a
+// provider instance, i.e. one Terraform run, so refreshing many resources
+// referring to the same cluster does not fetch it again and again. Entries are
+// dropped whenever the cluster or one of its node pools is changed, see
+// providerMeta.lockCluster. Waiters poll the API directly and bypass the cache.
+type apiCache struct {
+	mu sync.Mutex
+
+	clusters   map[string]*symbiosis.Cluster
+	identities map[string]*symbiosis.ClusterIdentity
+	nodePools  map[string]*symbiosis.NodePool
+	nodeTypes  []*symbiosis.NodeType
+	quota      *teamQuota
+	usage      *teamUsage
+
+	clusterMisses int
+	prefetched    bool
+}
+
+func newAPICache() *apiCache {
+	return &apiCache{
+		clusters:   map[string]*symbiosis.Cluster{},
+		identities: map[string]*symbiosis.ClusterIdentity{},
+		nodePools:  map[string]*symbiosis.NodePool{},
+	}
+}
+
+// describeCluster returns the cluster name. An API client for ctx is only
+// built when the cluster is not cached.
+func (c *apiCache) describeCluster(ctx context.Context, m *providerMeta, name string) (*symbiosis.Cluster, error) {
+	c.mu.Lock()
+	cluster, ok := c.clusters[name]
+	prefetch := false
+	if !ok && !c.prefetched {
+		c.clusterMisses++
+		prefetch = c.clusterMisses >= clusterPrefetchThreshold
+		c.prefetched = prefetch
+	}
+	c.mu.Unlock()
+
+	if ok {
+		return cluster, nil
+	}
+
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefetch {
+		c.prefetchClusters(client)
+
+		c.mu.Lock()
+		cluster, ok = c.clusters[name]
+		c.mu.Unlock()
+		if ok {
+			return cluster, nil
+		}
+	}
+
+	cluster, err = client.Cluster.Describe(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.clusters[name] = cluster
+	c.mu.Unlock()
+
+	return cluster, nil
+}
+
+// prefetchClusters fills the cache from the cluster list. Failures are only
+// logged, the clusters are then described one by one as before.
+func (c *apiCache) prefetchClusters(client *symbiosis.Client) {
+	log.Printf("[DEBUG] Prefetching clusters")
+
+	for page := 0; ; page++ {
+		list, err := client.Cluster.List(clusterListPageSize, page)
+		if err != nil {
+			log.Printf("[WARN] Prefetching clusters failed: %s", err)
+			return
+		}
+
+		c.mu.Lock()
+		for _, cluster := range list.Clusters {
+			// Policy, cost and replacement checks read the node pools of cached
+			// clusters, so list entries without them are described on demand
+			if cluster.NodePools == nil {
+				continue
+			}
+			if _, ok := c.clusters[cluster.Name]; !ok {
+				c.clusters[cluster.Name] = cluster
+			}
+		}
+		c.mu.Unlock()
+
+		if len(list.Clusters) < clusterListPageSize {
+			return
+		}
+	}
+}
+
+func (c *apiCache) clusterIdentity(ctx context.Context, m *providerMeta, name string) (*symbiosis.ClusterIdentity, error) {
+	c.mu.Lock()
+	identity, ok := c.identities[name]
+	c.mu.Unlock()
+
+	if ok {
+		return identity, nil
+	}
+
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err = client.Cluster.GetIdentity(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.identities[name] = identity
+	c.mu.Unlock()
+
+	return identity, nil
+}
+
+// describeNodePool returns the node pool id. Pools are only cached from
+// NodePool.Describe, the pools embedded in cluster responses are not known to
+// carry all of their attributes.
+func (c *apiCache) describeNodePool(ctx context.Context, m *providerMeta, id string) (*symbiosis.NodePool, error) {
+	c.mu.Lock()
+	nodePool, ok := c.nodePools[id]
+	c.mu.Unlock()
+
+	if ok {
+		return nodePool, nil
+	}
+
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodePool, err = client.NodePool.Describe(id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.nodePools[id] = nodePool
+	c.mu.Unlock()
+
+	return nodePool, nil
+}
+
+// listNodeTypes returns the node types with their pricing. They do not change
+// during a run and are never invalidated.
+func (c *apiCache) listNodeTypes(ctx context.Context, m *providerMeta) ([]*symbiosis.NodeType, error) {
+	c.mu.Lock()
+	nodeTypes := c.nodeTypes
+	c.mu.Unlock()
+
+	if nodeTypes != nil {
+		return nodeTypes, nil
+	}
+
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeTypes, err = client.NodeType.List()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.nodeTypes = nodeTypes
+	c.mu.Unlock()
+
+	return nodeTypes, nil
+}
+
+func (c *apiCache) teamQuota(ctx context.Context, m *providerMeta) (*teamQuota, error) {
+	c.mu.Lock()
+	quota := c.quota
+	c.mu.Unlock()
+
+	if quota != nil {
+		return quota, nil
+	}
+
+	quota, err := m.fetchTeamQuota(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.quota = quota
+	c.mu.Unlock()
+
+	return quota, nil
+}
+
+func (c *apiCache) teamUsage(ctx context.Context, m *providerMeta) (*teamUsage, error) {
+	c.mu.Lock()
+	usage := c.usage
+	c.mu.Unlock()
+
+	if usage != nil {
+		return usage, nil
+	}
+
+	usage, err := m.fetchTeamUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.usage = usage
+	c.mu.Unlock()
+
+	return usage, nil
+}
+
+// invalidateCluster drops the cluster, its identity and its node pools.
+func (c *apiCache) invalidateCluster(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.clusters, name)
+	delete(c.identities, name)
+	// Usage changes with every cluster and node pool
+	c.usage = nil
+	for id, nodePool := range c.nodePools {
+		if nodePool.ClusterName == name {
+			delete(c.nodePools, id)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package symbiosis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+// costSettings configures cost estimates. The API prices node types only, so
+// control plane prices are taken from the provider configuration.
+type costSettings struct {
+	currency                  string
+	controlPlaneMonthlyCost   float64
+	haControlPlaneMonthlyCost float64
+}
+
+// nodeTypeMonthlyCost returns the monthly price of one node of the given type.
+func (m *providerMeta) nodeTypeMonthlyCost(ctx context.Context, nodeTypeName string) (float64, error) {
+	nodeTypes, err := m.cache.listNodeTypes(ctx, m)
+	if err != nil {
+		return 0, fmt.Errorf("Error listing node types: %w", err)
+	}
+
+	for _, nodeType := range nodeTypes {
+		if nodeType.Name != nodeTypeName {
+			continue
+		}
+		if nodeType.Product != nil {
+			for _, cost := range nodeType.Product.ProductCosts {
+				if strings.EqualFold(cost.Currency, m.costs.currency) {
+					return float64(cost.UnitAmount), nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("node type %s has no price in %s", nodeTypeName, m.costs.currency)
+	}
+
+	return 0, fmt.Errorf("unknown node type %s", nodeTypeName)
+}
+
+// nodePoolMonthlyCost prices a pool at its largest size, i.e. the autoscaling
+// max_size when autoscaling is enabled.
+func (m *providerMeta) nodePoolMonthlyCost(ctx context.Context, nodeTypeName string, quantity int, autoscaling symbiosis.AutoscalingSettings) (float64, error) {
+	price, err := m.nodeTypeMonthlyCost(ctx, nodeTypeName)
+	if err != nil {
+		return 0, err
+	}
+
+	if autoscaling.Enabled {
+		quantity = autoscaling.MaxSize
+	}
+	return roundCost(price * float64(quantity)), nil
+}
+
+func (m *providerMeta) controlPlaneMonthlyCost(highlyAvailable bool) float64 {
+	if highlyAvailable {
+		return m.costs.haControlPlaneMonthlyCost
+	}
+	return m.costs.controlPlaneMonthlyCost
+}
+
+// clusterMonthlyCost prices the control plane and every node pool of cluster.
+// Pools whose node type cannot be priced are left out and logged.
+func (m *providerMeta) clusterMonthlyCost(ctx context.Context, cluster *symbiosis.Cluster) float64 {
+	total := m.controlPlaneMonthlyCost(cluster.IsHighlyAvailable)
+
+	for _, nodePool := range cluster.NodePools {
+		cost, err := m.nodePoolMonthlyCost(ctx, nodePool.NodeTypeName, nodePool.DesiredQuantity, nodePool.Autoscaling)
+		if err != nil {
+			log.Printf("[WARN] Cannot estimate cost of node pool %s: %s", nodePool.Name, err)
+			continue
+		}
+		total += cost
+	}
+
+	return roundCost(total)
+}
+
+// roundCost rounds to cents to keep float noise out of plans.
+func roundCost(cost float64) float64 {
+	return math.Round(cost*100) / 100
+}
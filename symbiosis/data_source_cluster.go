@@ -6,7 +6,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/symbiosis-cloud/symbiosis-go"
 )
 
 func dataSourceCluster() *schema.Resource {
@@ -65,16 +64,16 @@ func dataSourceClusterRead(ctx context.Context, d *schema.ResourceData, meta int
 
 	log.Printf("[DEBUG] Reading cluster: %s", clusterName)
 
-	client := meta.(*symbiosis.Client)
+	m := meta.(*providerMeta)
 
-	cluster, err := client.Cluster.Describe(clusterName)
+	cluster, err := m.cache.describeCluster(ctx, m, clusterName)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameCluster)
 	}
 
-	identity, err := client.Cluster.GetIdentity(clusterName)
+	identity, err := m.cache.clusterIdentity(ctx, m, clusterName)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameCluster)
 	}
 
 	d.SetId(cluster.Name)
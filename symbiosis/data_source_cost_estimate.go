@@ -0,0 +1,100 @@
+package symbiosis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+func dataSourceCostEstimate() *schema.Resource {
+	return &schema.Resource{
+		Description: `
+    Estimates the monthly price of a set of node pools, e.g. before they are created.
+    `,
+		ReadContext: dataSourceCostEstimateRead,
+		Schema: map[string]*schema.Schema{
+			"node_pool": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"node_type": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Node type of the pool.",
+						},
+						"quantity": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "Number of nodes, use the autoscaling max_size for autoscaled pools.",
+						},
+						"monthly_cost": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "Estimated monthly price of the pool.",
+						},
+					},
+				},
+			},
+			"control_planes": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of control planes to include, priced with the provider control_plane_monthly_cost.",
+			},
+			"ha_control_planes": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of highly available control planes to include, priced with the provider ha_control_plane_monthly_cost.",
+			},
+			"currency": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Currency of the estimate, the provider cost_currency.",
+			},
+			"monthly_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Estimated monthly price of all node pools and control planes.",
+			},
+		},
+	}
+}
+
+func dataSourceCostEstimateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*providerMeta)
+
+	total := float64(d.Get("control_planes").(int))*m.controlPlaneMonthlyCost(false) +
+		float64(d.Get("ha_control_planes").(int))*m.controlPlaneMonthlyCost(true)
+
+	nodePools := d.Get("node_pool").([]interface{})
+	id := fmt.Sprintf("%s-%d", m.costs.currency, schema.HashString(fmt.Sprint(nodePools, d.Get("control_planes"), d.Get("ha_control_planes"))))
+
+	for i, raw := range nodePools {
+		nodePool := raw.(map[string]interface{})
+
+		cost, err := m.nodePoolMonthlyCost(ctx, nodePool["node_type"].(string), nodePool["quantity"].(int), symbiosis.AutoscalingSettings{})
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("Cannot estimate cost of node_pool %d: %w", i, err))
+		}
+
+		nodePool["monthly_cost"] = cost
+		total += cost
+	}
+
+	d.SetId(id)
+	d.Set("node_pool", nodePools)
+	d.Set("currency", m.costs.currency)
+	d.Set("monthly_cost", roundCost(total))
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package symbiosis
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceTeamQuota() *schema.Resource {
+	return &schema.Resource{
+		Description: `
+    Describes the quota and the current usage of the team owning the API key. Usage is counted from the clusters and node pools of the team. A limit of 0 means unlimited. The API does not report team quotas yet, so until it does every limit is 0.
+    `,
+		ReadContext: dataSourceTeamQuotaRead,
+		Schema: map[string]*schema.Schema{
+			"max_clusters": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Maximum number of clusters.",
+			},
+			"used_clusters": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of clusters in use.",
+			},
+			"max_nodes": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Maximum number of nodes across all clusters.",
+			},
+			"used_nodes": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of nodes in use, counting the desired quantity of every node pool.",
+			},
+			"max_vcpu": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Maximum number of vCPUs across all nodes.",
+			},
+			"used_vcpu": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of vCPUs in use.",
+			},
+		},
+	}
+}
+
+func dataSourceTeamQuotaRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*providerMeta)
+
+	quota, err := m.cache.teamQuota(ctx, m)
+	if err != nil {
+		return apiErrorDiags(err, nameTeamQuota)
+	}
+	usage, err := m.cache.teamUsage(ctx, m)
+	if err != nil {
+		return apiErrorDiags(err, nameTeamQuota)
+	}
+
+	d.SetId("team-quota")
+	d.Set("max_clusters", quota.MaxClusters)
+	d.Set("used_clusters", usage.Clusters)
+	d.Set("max_nodes", quota.MaxNodes)
+	d.Set("used_nodes", usage.Nodes)
+	d.Set("max_vcpu", quota.MaxVcpu)
+	d.Set("used_vcpu", usage.Vcpu)
+
+	return nil
+}
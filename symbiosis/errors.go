@@ -0,0 +1,169 @@
+package symbiosis
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+// isNotFoundError reports whether err is the API telling us the requested
+// object (or its parent cluster) no longer exists.
+func isNotFoundError(err error) bool {
+	return apiStatus(err) == http.StatusNotFound
+}
+
+// isConflictError reports whether err is the API rejecting a create because an
+// object with the same name already exists.
+func isConflictError(err error) bool {
+	return apiStatus(err) == http.StatusConflict
+}
+
+// apiStatus returns the HTTP status of an API error, or 0 when err did not come
+// from the API.
+func apiStatus(err error) int {
+	status, _ := apiErrorStatus(err)
+	return status
+}
+
+// apiField maps a field name used in API error messages to the attribute
+// configuring it.
+type apiField struct {
+	name      string
+	attribute string
+}
+
+// apiErrorContext describes how API errors of one resource type are reported.
+type apiErrorContext struct {
+	// action completes "The API key is not allowed to ..."
+	action string
+	// fields are matched against error messages in order, so more specific
+	// names come first
+	fields []apiField
+}
+
+var apiErrorContexts = map[string]apiErrorContext{
+	nameCluster: {
+		action: "manage clusters",
+		fields: []apiField{
+			{"kubeVersion", "kube_version"},
+			{"isHighlyAvailable", "is_highly_available"},
+			{"regionName", "region"},
+			{"region", "region"},
+			{"name", "name"},
+		},
+	},
+	nameNodePool: {
+		action: "manage node pools",
+		fields: []apiField{
+			{"nodeTypeName", "node_type"},
+			{"nodeType", "node_type"},
+			{"clusterName", "cluster"},
+			{"kubeVersion", "kube_version"},
+			{"minSize", "autoscaling"},
+			{"maxSize", "autoscaling"},
+			{"autoscaling", "autoscaling"},
+			{"taints", "taint"},
+			{"labels", "labels"},
+			{"quantity", "quantity"},
+			{"name", "name"},
+		},
+	},
+	nameTeamMember: {
+		action: "manage team members, which requires an API key with role ADMIN",
+		fields: []apiField{
+			{"email", "email"},
+			{"role", "role"},
+		},
+	},
+	nameClusterServiceAccount: {
+		action: "manage cluster service accounts",
+		fields: []apiField{
+			{"clusterName", "cluster_name"},
+		},
+	},
+}
+
+// apiErrorDiags turns err into a diagnostic. API errors get a summary naming
+// the status, the API's message as detail, a hint for authentication and
+// permission failures and, when the message names a field of resourceName, an
+// attribute path pointing at it. Other errors are reported as is.
+func apiErrorDiags(err error, resourceName string) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	status, message := apiErrorStatus(err)
+	if status == 0 {
+		return diag.FromErr(err)
+	}
+
+	errCtx := apiErrorContexts[resourceName]
+
+	d := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Symbiosis API error: %d %s", status, http.StatusText(status)),
+		Detail:   message,
+	}
+
+	switch status {
+	case http.StatusUnauthorized:
+		d.Detail += "\n\nThe API key was not accepted. Check the api_key provider argument or SYMBIOSIS_API_KEY, the key may have been revoked."
+	case http.StatusForbidden:
+		action := errCtx.action
+		if action == "" {
+			action = "perform this operation"
+		}
+		d.Detail += fmt.Sprintf("\n\nThe API key is not allowed to %s. Use an API key whose role grants this permission.", action)
+	default:
+		if attribute := apiErrorAttribute(message, errCtx.fields); attribute != "" {
+			d.AttributePath = cty.GetAttrPath(attribute)
+		}
+	}
+
+	return diag.Diagnostics{d}
+}
+
+// apiFieldPatterns match the apiField names as words, compiled once.
+var apiFieldPatterns = map[string]*regexp.Regexp{}
+
+func init() {
+	for _, errCtx := range apiErrorContexts {
+		for _, field := range errCtx.fields {
+			apiFieldPatterns[field.name] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(field.name) + `\b`)
+		}
+	}
+}
+
+// apiErrorStatus returns the HTTP status and message of an API error, or 0
+// when err did not come from the API. The message of a wrapped API error keeps
+// the context it was wrapped with.
+func apiErrorStatus(err error) (int, string) {
+	var genericErr *symbiosis.GenericError
+	if !errors.As(err, &genericErr) || genericErr.Status == 0 {
+		return 0, ""
+	}
+
+	if err != error(genericErr) {
+		return int(genericErr.Status), err.Error()
+	}
+
+	message := genericErr.Message
+	if message == "" {
+		message = genericErr.ErrorType
+	}
+	return int(genericErr.Status), message
+}
+
+func apiErrorAttribute(message string, fields []apiField) string {
+	for _, field := range fields {
+		if apiFieldPatterns[field.name].MatchString(message) {
+			return field.attribute
+		}
+	}
+	return ""
+}
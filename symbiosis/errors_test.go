@@ -0,0 +1,56 @@
+package symbiosis
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+func TestAPIErrorStatus(t *testing.T) {
+	notFound := &symbiosis.GenericError{Status: 404, ErrorType: "Not Found", Message: "node pool not found"}
+
+	cases := []struct {
+		name     string
+		err      error
+		status   int
+		message  string
+		notFound bool
+	}{
+		{
+			name:     "API error",
+			err:      notFound,
+			status:   404,
+			message:  "node pool not found",
+			notFound: true,
+		},
+		{
+			name:     "wrapped API error",
+			err:      fmt.Errorf("Error describing node pool: %w", notFound),
+			status:   404,
+			message:  "Error describing node pool: Error: Not Found (404) - node pool not found",
+			notFound: true,
+		},
+		{
+			name: "status in the text of another error",
+			err:  errors.New("Error waiting for cluster prod-500 to become ACTIVE: timeout"),
+		},
+		{
+			name: "API error flattened to text",
+			err:  fmt.Errorf("Error describing node pool 404: %s", notFound),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, message := apiErrorStatus(c.err)
+			if status != c.status || message != c.message {
+				t.Errorf("got %d %q, want %d %q", status, message, c.status, c.message)
+			}
+			if isNotFoundError(c.err) != c.notFound {
+				t.Errorf("isNotFoundError = %t, want %t", !c.notFound, c.notFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package symbiosis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// withIdempotencyKey lets the API recognise repeated create requests and
+// answer them with the object created first.
+func withIdempotencyKey(key string) symbiosis.ClientOption {
+	return func(c *resty.Client) {
+		c.SetHeader(idempotencyKeyHeader, key)
+	}
+}
+
+// newIdempotencyKey returns a key for one create. Retries of that create send
+// it again, while a later create under the same name, e.g. the replacement of
+// a deleted object, gets a key of its own. Across applies, objects whose
+// create response was lost are found by name instead.
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("Error generating idempotency key: %w", err)
+	}
+	return "terraform-" + hex.EncodeToString(b), nil
+}
+
+// isLostResponseError reports whether err leaves it unknown if the request
+// reached the API: the request timed out in transit or a gateway in front of
+// the API failed. Errors raised before the request was sent, such as DNS or
+// TLS failures, and cancellation are not.
+func isLostResponseError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var genericErr *symbiosis.GenericError
+	if errors.As(err, &genericErr) {
+		switch genericErr.Status {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+
+	return false
+}
+
+// createIdempotently calls create with a client that sends a new idempotency
+// key, the same on every attempt. When the response to an attempt is lost,
+// lookup checks whether the object was created anyway before create is tried
+// again. Both callbacks record the object they find themselves.
+func createIdempotently(ctx context.Context, m *providerMeta, timeout time.Duration, create func(*symbiosis.Client) error, lookup func(*symbiosis.Client) (bool, error)) error {
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return err
+	}
+
+	client, err := m.client(ctx, withIdempotencyKey(key))
+	if err != nil {
+		return err
+	}
+
+	return m.retry(ctx, timeout, func() *resource.RetryError {
+		err := create(client)
+		if err == nil {
+			return nil
+		}
+		if !isLostResponseError(err) {
+			return resource.NonRetryableError(err)
+		}
+
+		log.Printf("[WARN] Lost response to create request with idempotency key %s: %s", key, err)
+
+		found, lerr := lookup(client)
+		if lerr != nil {
+			return resource.RetryableError(fmt.Errorf("%s, and looking up the object failed: %s", err, lerr))
+		}
+		if found {
+			log.Printf("[INFO] Create request with idempotency key %s succeeded despite the lost response", key)
+			return nil
+		}
+
+		return resource.RetryableError(err)
+	})
+}
@@ -0,0 +1,327 @@
+package symbiosis
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+const drainPollInterval = 5 * time.Second
+
+type drainOptions struct {
+	Timeout            time.Duration
+	IgnoreDaemonSets   bool
+	DeleteEmptyDirData bool
+}
+
+// kubernetesClient is a minimal client for the handful of Kubernetes API calls
+// needed to cordon and drain nodes, authenticated with the cluster identity.
+type kubernetesClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+type kubernetesPod struct {
+	Metadata struct {
+		Name            string            `json:"name"`
+		Namespace       string            `json:"namespace"`
+		UID             string            `json:"uid"`
+		Annotations     map[string]string `json:"annotations"`
+		OwnerReferences []struct {
+			Kind       string `json:"kind"`
+			Controller *bool  `json:"controller"`
+		} `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		Volumes []struct {
+			Name     string    `json:"name"`
+			EmptyDir *struct{} `json:"emptyDir"`
+		} `json:"volumes"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+type kubernetesPodList struct {
+	Items []kubernetesPod `json:"items"`
+}
+
+func expandDrainOptions(input []interface{}) *drainOptions {
+	if len(input) == 0 || input[0] == nil {
+		return nil
+	}
+
+	settings := input[0].(map[string]interface{})
+	// timeout is validated by the schema, so it always parses
+	timeout, _ := time.ParseDuration(settings["timeout"].(string))
+
+	return &drainOptions{
+		Timeout:            timeout,
+		IgnoreDaemonSets:   settings["ignore_daemonsets"].(bool),
+		DeleteEmptyDirData: settings["delete_emptydir_data"].(bool),
+	}
+}
+
+func newKubernetesClient(client *symbiosis.Client, clusterName string) (*kubernetesClient, error) {
+	cluster, err := client.Cluster.Describe(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := client.Cluster.GetIdentity(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := tls.X509KeyPair([]byte(identity.CertificatePem), []byte(identity.PrivateKeyPem))
+	if err != nil {
+		return nil, fmt.Errorf("Error loading cluster identity: %w", err)
+	}
+
+	ca := x509.NewCertPool()
+	if !ca.AppendCertsFromPEM([]byte(identity.ClusterCertificateAuthorityPem)) {
+		return nil, errors.New("Error loading cluster certificate authority")
+	}
+
+	endpoint := cluster.APIServerEndpoint
+	if !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "https://" + endpoint
+	}
+
+	return &kubernetesClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		httpClient: &http.Client{
+			Timeout: time.Minute,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					Certificates: []tls.Certificate{certificate},
+					RootCAs:      ca,
+					MinVersion:   tls.VersionTLS12,
+				},
+			},
+		},
+	}, nil
+}
+
+func (k *kubernetesClient) do(ctx context.Context, method string, path string, contentType string, body interface{}, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, k.endpoint+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if out != nil {
+		return resp.StatusCode, json.Unmarshal(respBody, out)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (k *kubernetesClient) cordonNode(ctx context.Context, nodeName string) error {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"unschedulable": true,
+		},
+	}
+	_, err := k.do(ctx, http.MethodPatch, "/api/v1/nodes/"+url.PathEscape(nodeName), "application/merge-patch+json", patch, nil)
+	return err
+}
+
+func (k *kubernetesClient) listPodsOnNode(ctx context.Context, nodeName string) ([]kubernetesPod, error) {
+	query := url.Values{"fieldSelector": []string{"spec.nodeName=" + nodeName}}
+
+	var pods kubernetesPodList
+	_, err := k.do(ctx, http.MethodGet, "/api/v1/pods?"+query.Encode(), "", nil, &pods)
+	if err != nil {
+		return nil, err
+	}
+
+	return pods.Items, nil
+}
+
+// evictPod requests eviction of the pod, retrying while a PodDisruptionBudget
+// blocks it until ctx expires.
+func (k *kubernetesClient) evictPod(ctx context.Context, pod kubernetesPod) error {
+	eviction := map[string]interface{}{
+		"apiVersion": "policy/v1",
+		"kind":       "Eviction",
+		"metadata": map[string]interface{}{
+			"name":      pod.Metadata.Name,
+			"namespace": pod.Metadata.Namespace,
+		},
+	}
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/eviction", url.PathEscape(pod.Metadata.Namespace), url.PathEscape(pod.Metadata.Name))
+
+	for {
+		status, err := k.do(ctx, http.MethodPost, path, "application/json", eviction, nil)
+		if err == nil || status == http.StatusNotFound {
+			return nil
+		}
+		if status != http.StatusTooManyRequests {
+			return err
+		}
+
+		log.Printf("[DEBUG] Eviction of pod %s/%s blocked by disruption budget, retrying", pod.Metadata.Namespace, pod.Metadata.Name)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out evicting pod %s/%s: %s", pod.Metadata.Namespace, pod.Metadata.Name, err)
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+func (k *kubernetesClient) waitForPodDeleted(ctx context.Context, pod kubernetesPod) error {
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", url.PathEscape(pod.Metadata.Namespace), url.PathEscape(pod.Metadata.Name))
+
+	for {
+		var current kubernetesPod
+		status, err := k.do(ctx, http.MethodGet, path, "", nil, &current)
+		if status == http.StatusNotFound || (err == nil && current.Metadata.UID != pod.Metadata.UID) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for pod %s/%s to terminate", pod.Metadata.Namespace, pod.Metadata.Name)
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// drainNode cordons the node and evicts its pods the way `kubectl drain` does,
+// honoring PodDisruptionBudgets.
+func (k *kubernetesClient) drainNode(ctx context.Context, nodeName string, opts *drainOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	log.Printf("[DEBUG] Draining node %s", nodeName)
+
+	err := k.cordonNode(ctx, nodeName)
+	if err != nil {
+		return fmt.Errorf("Error cordoning node %s: %s", nodeName, err)
+	}
+
+	pods, err := k.listPodsOnNode(ctx, nodeName)
+	if err != nil {
+		return fmt.Errorf("Error listing pods on node %s: %s", nodeName, err)
+	}
+
+	evicted := make([]kubernetesPod, 0, len(pods))
+	for _, pod := range pods {
+		evict, err := shouldEvictPod(pod, opts)
+		if err != nil {
+			return fmt.Errorf("Cannot drain node %s: %s", nodeName, err)
+		}
+		if !evict {
+			continue
+		}
+
+		err = k.evictPod(ctx, pod)
+		if err != nil {
+			return err
+		}
+		evicted = append(evicted, pod)
+	}
+
+	for _, pod := range evicted {
+		err := k.waitForPodDeleted(ctx, pod)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func shouldEvictPod(pod kubernetesPod, opts *drainOptions) (bool, error) {
+	// Mirror pods are managed by the kubelet and cannot be evicted
+	if _, ok := pod.Metadata.Annotations["kubernetes.io/config.mirror"]; ok {
+		return false, nil
+	}
+
+	if pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+		return false, nil
+	}
+
+	for _, owner := range pod.Metadata.OwnerReferences {
+		if owner.Kind == "DaemonSet" && owner.Controller != nil && *owner.Controller {
+			if opts.IgnoreDaemonSets {
+				return false, nil
+			}
+			return false, fmt.Errorf("pod %s/%s is managed by a DaemonSet, set ignore_daemonsets to drain anyway", pod.Metadata.Namespace, pod.Metadata.Name)
+		}
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil && !opts.DeleteEmptyDirData {
+			return false, fmt.Errorf("pod %s/%s uses emptyDir volume %s, set delete_emptydir_data to drain anyway", pod.Metadata.Namespace, pod.Metadata.Name, volume.Name)
+		}
+	}
+
+	return true, nil
+}
+
+// drainNodes drains the given nodes of a cluster one after another.
+func drainNodes(ctx context.Context, client *symbiosis.Client, clusterName string, nodes []*symbiosis.Node, opts *drainOptions) error {
+	if opts == nil || len(nodes) == 0 {
+		return nil
+	}
+
+	k, err := newKubernetesClient(client, clusterName)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		err := k.drainNode(ctx, node.Name, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
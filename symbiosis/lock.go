@@ -0,0 +1,84 @@
+package symbiosis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+// clusterLocks serializes mutating operations per cluster name, since the API
+// rejects concurrent changes to the node pools of one cluster.
+type clusterLocks struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+func newClusterLocks() *clusterLocks {
+	return &clusterLocks{locks: map[string]chan struct{}{}}
+}
+
+// lock blocks until the lock for clusterName is free or ctx is done and
+// returns the function releasing it.
+func (l *clusterLocks) lock(ctx context.Context, clusterName string) (func(), error) {
+	l.mu.Lock()
+	ch, ok := l.locks[clusterName]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		l.locks[clusterName] = ch
+	}
+	l.mu.Unlock()
+
+	select {
+	case ch <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for other operations on cluster %s: %s", clusterName, ctx.Err())
+	}
+
+	log.Printf("[DEBUG] Locked cluster %s", clusterName)
+	return func() {
+		<-ch
+		log.Printf("[DEBUG] Unlocked cluster %s", clusterName)
+	}, nil
+}
+
+// lockCluster takes the lock for clusterName before the cluster or its node
+// pools are changed. Cached responses for the cluster are dropped both when
+// the lock is taken and when it is released.
+func (m *providerMeta) lockCluster(ctx context.Context, clusterName string) (func(), error) {
+	unlock, err := m.clusterLocks.lock(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.invalidateCluster(clusterName)
+
+	return func() {
+		m.cache.invalidateCluster(clusterName)
+		unlock()
+	}, nil
+}
+
+// lockClusterForNodePool takes the cluster lock and waits for the cluster to be
+// ACTIVE, e.g. after an upgrade, before node pools are created or updated. A cluster
+// that no longer exists is not waited for.
+func lockClusterForNodePool(ctx context.Context, m *providerMeta, client *symbiosis.Client, clusterName string, timeout time.Duration) (func(), error) {
+	unlock, err := m.lockCluster(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	failed, err := waitForClusterActive(ctx, m, client, clusterName, timeout)
+	if failed {
+		unlock()
+		return nil, fmt.Errorf("Cannot change node pools: %w", err)
+	}
+	if err != nil && !isNotFoundError(err) {
+		unlock()
+		return nil, fmt.Errorf("Error waiting for cluster %s to become ACTIVE: %w", clusterName, err)
+	}
+
+	return unlock, nil
+}
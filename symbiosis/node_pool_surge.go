@@ -0,0 +1,199 @@
+package symbiosis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/symbiosis-cloud/symbiosis-go"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// surgeReplaceNodePool replaces oldPool with a pool of the new node type without
+// dropping capacity. The new pool grows in steps of max_surge while the old pool
+// shrinks by the same amount, and the old pool is drained and deleted once the
+// new one has reached its full size.
+func surgeReplaceNodePool(ctx context.Context, d *schema.ResourceData, m *providerMeta, oldPool *symbiosis.NodePool) (*symbiosis.NodePool, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	input := expandNodePoolInput(d)
+	drain := expandDrainOptions(d.Get("drain").([]interface{}))
+	timeout := d.Timeout(schema.TimeoutUpdate)
+
+	target := input.Quantity
+	if input.Autoscaling.Enabled {
+		// Start at the current size so the autoscaler takes over without losing capacity
+		target = oldPool.DesiredQuantity
+	}
+
+	step := d.Get("max_surge").(int)
+	if step <= 0 || step > target || input.Autoscaling.Enabled {
+		step = target
+	}
+
+	log.Printf("[DEBUG] Surge replacing node pool %s with node type %s in steps of %d", oldPool.ID, input.NodeTypeName, step)
+
+	// Pool names are unique within a cluster, so the replacement gets a
+	// temporary name while the old pool still exists
+	input.Name = surgeNodePoolName(d.Get("name").(string), input.NodeTypeName)
+	input.Quantity = step
+	var newPool *symbiosis.NodePool
+	err = createIdempotently(ctx, m, timeout, func(c *symbiosis.Client) (err error) {
+		newPool, err = c.NodePool.Create(input)
+		return err
+	}, func(c *symbiosis.Client) (bool, error) {
+		existing, err := lookupNodePoolByName(c, input.ClusterName, input.Name)
+		if existing != nil {
+			newPool = existing
+		}
+		return existing != nil, err
+	})
+	newQuantity := step
+	if isConflictError(err) {
+		// The name belongs to this provider, so the pool was left behind by a
+		// surge that failed before and the replacement continues with it
+		newPool, err = lookupNodePoolByName(client, input.ClusterName, input.Name)
+		if err == nil && newPool == nil {
+			err = fmt.Errorf("node pool %s conflicts but cannot be found", input.Name)
+		}
+		if err == nil {
+			log.Printf("[INFO] Continuing surge replacement with existing node pool %s (%s)", newPool.Name, newPool.ID)
+			if newPool.DesiredQuantity > newQuantity {
+				newQuantity = newPool.DesiredQuantity
+			}
+			if newQuantity > target {
+				newQuantity = target
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error creating replacement node pool: %w", err)
+	}
+
+	// A failed surge leaves both pools running, report where it stopped
+	kept := func(err error) error {
+		return fmt.Errorf("%w. Replacement node pool %s (ID %s) was kept and the next apply continues the replacement with it", err, newPool.Name, newPool.ID)
+	}
+
+	err = waitForNodePoolReady(ctx, m, client, newPool.ID, newQuantity, timeout)
+	if err != nil {
+		return nil, kept(fmt.Errorf("Replacement node pool %s did not become ready: %w", newPool.ID, err))
+	}
+
+	oldQuantity := oldPool.DesiredQuantity
+	for newQuantity < target {
+		// The old pool keeps at least one node until it is deleted at the end
+		if !oldPool.Autoscaling.Enabled && oldQuantity-step >= 1 {
+			err = scaleDownNodePool(ctx, client, oldPool.ID, oldQuantity-step, drain)
+			if err != nil {
+				return nil, kept(fmt.Errorf("Error scaling down node pool %s: %w", oldPool.ID, err))
+			}
+			oldQuantity -= step
+		}
+
+		newQuantity += step
+		if newQuantity > target {
+			newQuantity = target
+		}
+
+		err = client.NodePool.Update(newPool.ID, &symbiosis.NodePoolUpdateInput{
+			Quantity:    newQuantity,
+			Autoscaling: input.Autoscaling,
+		})
+		if err != nil {
+			return nil, kept(fmt.Errorf("Error scaling up replacement node pool %s: %w", newPool.ID, err))
+		}
+
+		err = waitForNodePoolReady(ctx, m, client, newPool.ID, newQuantity, timeout)
+		if err != nil {
+			return nil, kept(fmt.Errorf("Replacement node pool %s did not become ready: %w", newPool.ID, err))
+		}
+	}
+
+	remaining, err := client.NodePool.Describe(oldPool.ID)
+	if err != nil && !isNotFoundError(err) {
+		return nil, kept(err)
+	}
+	if remaining != nil {
+		err = drainNodes(ctx, client, remaining.ClusterName, remaining.Nodes, drain)
+		if err != nil {
+			return nil, kept(err)
+		}
+
+		err = client.NodePool.Delete(oldPool.ID)
+		if err != nil && !isNotFoundError(err) {
+			return nil, kept(fmt.Errorf("Error deleting replaced node pool %s: %w", oldPool.ID, err))
+		}
+	}
+
+	return newPool, nil
+}
+
+const surgeNameInfix = "-surge-"
+
+// surgeNodePoolName returns a name for the replacement of the pool configured
+// as name, e.g. "web-surge-3f9a1c". It depends on the new node type only, so a
+// surge retried after a failed apply conflicts with the replacement it created
+// before and takes it over.
+func surgeNodePoolName(name string, nodeType string) string {
+	sum := sha256.Sum256([]byte(nodeType))
+	return name + surgeNameInfix + hex.EncodeToString(sum[:3])
+}
+
+// isSurgeNodePoolName reports whether poolName is the name a surge replacement
+// of the pool configured as name was created with.
+func isSurgeNodePoolName(poolName string, name string) bool {
+	return strings.HasPrefix(poolName, name+surgeNameInfix)
+}
+
+// scaleDownNodePool lowers the desired quantity of a node pool, draining the
+// removed nodes first when drain options are given.
+func scaleDownNodePool(ctx context.Context, client *symbiosis.Client, id string, quantity int, drain *drainOptions) error {
+	nodePool, err := client.NodePool.Describe(id)
+	if err != nil {
+		return err
+	}
+
+	if drain != nil && quantity < nodePool.DesiredQuantity {
+		err = drainForScaleDown(ctx, client, nodePool, nodePool.DesiredQuantity-quantity, drain)
+		if err != nil {
+			return err
+		}
+	}
+
+	return client.NodePool.Update(id, &symbiosis.NodePoolUpdateInput{
+		Quantity:    quantity,
+		Autoscaling: nodePool.Autoscaling,
+	})
+}
+
+// waitForNodePoolReady waits until at least quantity nodes of the pool are active.
+func waitForNodePoolReady(ctx context.Context, m *providerMeta, client *symbiosis.Client, id string, quantity int, timeout time.Duration) error {
+	return m.retry(ctx, timeout, func() *resource.RetryError {
+		nodePool, err := client.NodePool.Describe(id)
+		if err != nil {
+			return resource.NonRetryableError(fmt.Errorf("Error describing node pool: %w", err))
+		}
+
+		active := 0
+		for _, node := range nodePool.Nodes {
+			if node.State == "ACTIVE" {
+				active++
+			}
+		}
+
+		if active < quantity {
+			return resource.RetryableError(fmt.Errorf("expected %d active nodes but found %d", quantity, active))
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,168 @@
+package symbiosis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/symbiosis-cloud/symbiosis-go"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// maxKubeletVersionSkew is the number of minor versions nodes may lag behind
+// the control plane, see https://kubernetes.io/releases/version-skew-policy/
+const maxKubeletVersionSkew = 2
+
+// validateNodePoolVersionSkew checks that the planned node pool version is
+// neither newer than the cluster nor too far behind it.
+func validateNodePoolVersionSkew(ctx context.Context, d *schema.ResourceDiff, m *providerMeta) error {
+	poolVersion, ok := d.GetOk("kube_version")
+	if !ok || !d.NewValueKnown("kube_version") || !d.NewValueKnown("cluster") {
+		return nil
+	}
+
+	cluster, err := m.cache.describeCluster(ctx, m, d.Get("cluster").(string))
+	if isNotFoundError(err) {
+		// The cluster is created in the same apply, it will default to its version
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return checkVersionSkew(cluster.KubeVersion, poolVersion.(string))
+}
+
+func checkVersionSkew(clusterVersion string, poolVersion string) error {
+	cv, err := version.NewVersion(clusterVersion)
+	if err != nil {
+		return fmt.Errorf("Cannot parse cluster Kubernetes version %q: %s", clusterVersion, err)
+	}
+
+	pv, err := version.NewVersion(poolVersion)
+	if err != nil {
+		return fmt.Errorf("Cannot parse node pool kube_version %q: %s", poolVersion, err)
+	}
+
+	cs, ps := cv.Segments(), pv.Segments()
+	if cs[0] != ps[0] {
+		return fmt.Errorf("node pool kube_version %s must have the same major version as the cluster (%s)", poolVersion, clusterVersion)
+	}
+	if ps[1] > cs[1] {
+		return fmt.Errorf("node pool kube_version %s must not be newer than the cluster version %s, upgrade the cluster first", poolVersion, clusterVersion)
+	}
+	if cs[1]-ps[1] > maxKubeletVersionSkew {
+		return fmt.Errorf("node pool kube_version %s is more than %d minor versions behind the cluster version %s", poolVersion, maxKubeletVersionSkew, clusterVersion)
+	}
+
+	return nil
+}
+
+// suppressKubeVersionDiff ignores kube_version changes that agree at the
+// precision of the less precise version, e.g. "1.24" configured while the API
+// reports "1.24.3", so they do not recycle all nodes on every apply.
+func suppressKubeVersionDiff(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+
+	ov, err := version.NewVersion(old)
+	if err != nil {
+		return false
+	}
+	nv, err := version.NewVersion(new)
+	if err != nil {
+		return false
+	}
+
+	precision := versionPrecision(old)
+	if p := versionPrecision(new); p < precision {
+		precision = p
+	}
+
+	oldSegments, newSegments := ov.Segments(), nv.Segments()
+	for i := 0; i < precision && i < len(oldSegments) && i < len(newSegments); i++ {
+		if oldSegments[i] != newSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// versionPrecision returns the number of segments written in v, e.g. 2 for "1.24".
+func versionPrecision(v string) int {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	return strings.Count(v, ".") + 1
+}
+
+// upgradeNodePool sets the new Kubernetes version on the pool and recycles its
+// nodes one at a time so each is replaced by a node running that version.
+func upgradeNodePool(ctx context.Context, d *schema.ResourceData, m *providerMeta, nodePool *symbiosis.NodePool, input *symbiosis.NodePoolUpdateInput) error {
+	client, err := m.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = client.NodePool.Update(nodePool.ID, input)
+	if err != nil {
+		return err
+	}
+
+	drain := expandDrainOptions(d.Get("drain").([]interface{}))
+	timeout := d.Timeout(schema.TimeoutUpdate)
+
+	for _, node := range nodePool.Nodes {
+		err := drainNodes(ctx, client, nodePool.ClusterName, []*symbiosis.Node{node}, drain)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[DEBUG] Recycling node %s of node pool %s to upgrade to %s", node.Name, nodePool.ID, input.KubeVersion)
+		err = client.Node.Recycle(node.Name)
+		if err != nil {
+			return fmt.Errorf("Error recycling node %s: %w", node.Name, err)
+		}
+
+		err = waitForNodeReplaced(ctx, m, client, nodePool.ID, node.ID, input.Quantity, timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitForNodeReplaced waits until the recycled node is gone and the pool is
+// back to quantity active nodes.
+func waitForNodeReplaced(ctx context.Context, m *providerMeta, client *symbiosis.Client, nodePoolID string, nodeID string, quantity int, timeout time.Duration) error {
+	return m.retry(ctx, timeout, func() *resource.RetryError {
+		nodePool, err := client.NodePool.Describe(nodePoolID)
+		if err != nil {
+			return resource.NonRetryableError(fmt.Errorf("Error describing node pool: %w", err))
+		}
+
+		active := 0
+		for _, node := range nodePool.Nodes {
+			if node.ID == nodeID {
+				return resource.RetryableError(fmt.Errorf("expected node %s to be replaced but it is still in state %s", node.Name, node.State))
+			}
+			if node.State == "ACTIVE" {
+				active++
+			}
+		}
+
+		if active < quantity {
+			return resource.RetryableError(fmt.Errorf("expected %d active nodes but found %d", quantity, active))
+		}
+
+		return nil
+	})
+}
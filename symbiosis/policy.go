@@ -0,0 +1,209 @@
+package symbiosis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// providerPolicy holds the guardrails configured in the provider policy block.
+// Empty lists and zero limits are not enforced.
+type providerPolicy struct {
+	AllowedRegions            []string
+	AllowedNodeTypes          []string
+	MaxNodesPerPool           int
+	MaxNodesPerCluster        int
+	RequireHAInRegions        []string
+	AllowedMemberEmailDomains []string
+}
+
+func policySchema() *schema.Schema {
+	stringSet := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: description,
+		}
+	}
+
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Guardrails checked when planning clusters, node pools and team members. A plan violating them fails.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"allowed_regions":              stringSet("Regions clusters may be created in."),
+				"allowed_node_types":           stringSet("Node types node pools may use."),
+				"require_ha_in_regions":        stringSet("Regions where clusters must set is_highly_available."),
+				"allowed_member_email_domains": stringSet("Email domains team members may be invited from, e.g. \"example.com\"."),
+				"max_nodes_per_pool": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Maximum size of a node pool, counting autoscaling max_size. 0 means unlimited.",
+				},
+				"max_nodes_per_cluster": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Maximum number of nodes across the node pools of a cluster, counting autoscaling max_size. 0 means unlimited.",
+				},
+			},
+		},
+	}
+}
+
+func expandPolicy(input []interface{}) *providerPolicy {
+	if len(input) == 0 || input[0] == nil {
+		return &providerPolicy{}
+	}
+
+	settings := input[0].(map[string]interface{})
+	return &providerPolicy{
+		AllowedRegions:            expandStringSet(settings["allowed_regions"]),
+		AllowedNodeTypes:          expandStringSet(settings["allowed_node_types"]),
+		MaxNodesPerPool:           settings["max_nodes_per_pool"].(int),
+		MaxNodesPerCluster:        settings["max_nodes_per_cluster"].(int),
+		RequireHAInRegions:        expandStringSet(settings["require_ha_in_regions"]),
+		AllowedMemberEmailDomains: expandStringSet(settings["allowed_member_email_domains"]),
+	}
+}
+
+func expandStringSet(v interface{}) []string {
+	set, ok := v.(*schema.Set)
+	if !ok {
+		return nil
+	}
+
+	values := make([]string, 0, set.Len())
+	for _, value := range set.List() {
+		values = append(values, value.(string))
+	}
+	return values
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// policedChange reports whether the policy applies to the diff. Existing
+// resources are only checked when a policed attribute changes, so tightening
+// the policy does not block plans for unrelated changes.
+func policedChange(d *schema.ResourceDiff, keys ...string) bool {
+	if d.Id() == "" {
+		return true
+	}
+	for _, key := range keys {
+		if d.HasChange(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *providerPolicy) checkCluster(d *schema.ResourceDiff) error {
+	if !policedChange(d, "region", "is_highly_available") {
+		return nil
+	}
+	if !d.NewValueKnown("region") {
+		return nil
+	}
+	region := d.Get("region").(string)
+
+	if len(p.AllowedRegions) > 0 && !containsFold(p.AllowedRegions, region) {
+		return fmt.Errorf("policy violation: region %s is not one of the allowed_regions %s", region, strings.Join(p.AllowedRegions, ", "))
+	}
+
+	if containsFold(p.RequireHAInRegions, region) && d.NewValueKnown("is_highly_available") && !d.Get("is_highly_available").(bool) {
+		return fmt.Errorf("policy violation: clusters in region %s must set is_highly_available = true", region)
+	}
+
+	return nil
+}
+
+// checkNodePool enforces node type and size limits. The cluster limit counts
+// the other pools of the cluster as they exist now, pools created in the same
+// apply are not known yet.
+func (p *providerPolicy) checkNodePool(ctx context.Context, d *schema.ResourceDiff, m *providerMeta) error {
+	if !policedChange(d, "node_type", "quantity", "autoscaling") {
+		return nil
+	}
+
+	if len(p.AllowedNodeTypes) > 0 && d.NewValueKnown("node_type") {
+		nodeType := d.Get("node_type").(string)
+		if !containsFold(p.AllowedNodeTypes, nodeType) {
+			return fmt.Errorf("policy violation: node_type %s is not one of the allowed_node_types %s", nodeType, strings.Join(p.AllowedNodeTypes, ", "))
+		}
+	}
+
+	size := plannedNodePoolSize(d)
+
+	if p.MaxNodesPerPool > 0 && size > p.MaxNodesPerPool {
+		return fmt.Errorf("policy violation: node pool may grow to %d nodes, more than max_nodes_per_pool %d", size, p.MaxNodesPerPool)
+	}
+
+	if p.MaxNodesPerCluster == 0 || !d.NewValueKnown("cluster") {
+		return nil
+	}
+
+	clusterName := d.Get("cluster").(string)
+	cluster, err := m.cache.describeCluster(ctx, m, clusterName)
+	if err != nil && !isNotFoundError(err) {
+		return err
+	}
+
+	total := size
+	if cluster != nil {
+		for _, nodePool := range cluster.NodePools {
+			if nodePool.ID == d.Id() {
+				continue
+			}
+			if nodePool.Autoscaling.Enabled {
+				total += nodePool.Autoscaling.MaxSize
+			} else {
+				total += nodePool.DesiredQuantity
+			}
+		}
+	}
+
+	if total > p.MaxNodesPerCluster {
+		return fmt.Errorf("policy violation: cluster %s may grow to %d nodes with this node pool, more than max_nodes_per_cluster %d", clusterName, total, p.MaxNodesPerCluster)
+	}
+
+	return nil
+}
+
+// plannedNodePoolSize is the largest size the planned node pool can reach.
+func plannedNodePoolSize(d *schema.ResourceDiff) int {
+	autoscaling := expandAutoscalingSettings(d.Get("autoscaling").([]interface{}))
+	if autoscaling.Enabled {
+		return autoscaling.MaxSize
+	}
+	return d.Get("quantity").(int)
+}
+
+func (p *providerPolicy) checkTeamMember(d *schema.ResourceDiff) error {
+	if !policedChange(d, "email") || len(p.AllowedMemberEmailDomains) == 0 || !d.NewValueKnown("email") {
+		return nil
+	}
+
+	email := d.Get("email").(string)
+	domain := email[strings.LastIndex(email, "@")+1:]
+	if !containsFold(p.AllowedMemberEmailDomains, domain) {
+		return fmt.Errorf("policy violation: email %s is not in one of the allowed_member_email_domains %s", email, strings.Join(p.AllowedMemberEmailDomains, ", "))
+	}
+
+	return nil
+}
@@ -3,9 +3,15 @@ package symbiosis
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
+	"github.com/go-resty/resty/v2"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/symbiosis-cloud/symbiosis-go"
 )
 
@@ -14,10 +20,48 @@ const (
 	nameNodePool              = "symbiosis_node_pool"
 	nameTeamMember            = "symbiosis_team_member"
 	nameClusterServiceAccount = "symbiosis_cluster_service_account"
+	nameCostEstimate          = "symbiosis_cost_estimate"
+	nameTeamQuota             = "symbiosis_team_quota"
 )
 
+// providerMeta is handed to every resource and data source as meta.
+type providerMeta struct {
+	apiKey       string
+	options      []symbiosis.ClientOption
+	transport    http.RoundTripper
+	pollInterval time.Duration
+	clusterLocks *clusterLocks
+	cache        *apiCache
+	policy       *providerPolicy
+	costs        costSettings
+	quotaCheck   string
+	planned      *plannedUsage
+
+	// replacementWarnings passes warnings between the two diffs of a cluster
+	// replacement by planned cluster name, see resourceClusterCustomizeDiff
+	replacementWarnings sync.Map
+}
+
+// client returns an API client whose requests are bound to ctx, so cancelling
+// ctx aborts requests in flight. All clients share one transport and with it
+// the connection pool. extra options apply to this client only. Operations
+// build one client and hand it to the helpers they call.
+func (m *providerMeta) client(ctx context.Context, extra ...symbiosis.ClientOption) (*symbiosis.Client, error) {
+	options := append(m.options[:len(m.options):len(m.options)], withContext(ctx, m.transport))
+	options = append(options, extra...)
+
+	c, err := symbiosis.NewClientFromAPIKey(m.apiKey, options...)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating Symbiosis client: %w", err)
+	}
+	return c, nil
+}
+
+// ProviderVersion is reported in the User-Agent of API requests, set from main at build time.
+var ProviderVersion = "dev"
+
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"api_key": {
 				Type:        schema.TypeString,
@@ -32,6 +76,61 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("SYMBIOSIS_ENDPOINT", "https://api.symbiosis.host"),
 				Description: "Endpoint for reaching the symbiosis API. Used for debugging or when accessed through a proxy.",
 			},
+			"poll_interval": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultPollInterval.String(),
+				ValidateFunc: validateDuration,
+				Description:  "Time between API calls while waiting for clusters and node pools to change state, e.g. \"10s\".",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of API requests in flight at once across all resources and data sources. 0 means unlimited.",
+			},
+			"quota_check": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "error",
+				ValidateFunc: validation.StringInSlice([]string{"error", "warn", "off"}, false),
+				Description:  "How plans exceeding the team quota are handled. The clusters and node pools planned in one run are checked together against the quota and the current usage of the team. \"error\" fails the plan, \"warn\" shows the violation in the quota_warning attribute of the planned cluster or node pool and \"off\" skips the check. The API does not report team quotas yet, so until it does every check passes.",
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Maximum number of API requests sent per second across all resources and data sources. 0 means unlimited.",
+			},
+			"cost_currency": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "USD",
+				Description: "Currency of the estimated_monthly_cost attributes and the symbiosis_cost_estimate data source.",
+			},
+			"control_plane_monthly_cost": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Monthly price of a control plane used for cost estimates, the API only prices nodes.",
+			},
+			"ha_control_plane_monthly_cost": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Monthly price of a highly available control plane used for cost estimates.",
+			},
+			"policy": policySchema(),
+			"user_agent_suffix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SYMBIOSIS_USER_AGENT_SUFFIX", nil),
+				Description: "Appended to the User-Agent of every API request, e.g. to identify a pipeline.",
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			nameCluster:               ResourceCluster(),
@@ -40,32 +139,95 @@ func Provider() *schema.Provider {
 			nameClusterServiceAccount: ResourceClusterServiceAccount(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			nameCluster: dataSourceCluster(),
+			nameCluster:      dataSourceCluster(),
+			nameCostEstimate: dataSourceCostEstimate(),
+			nameTeamQuota:    dataSourceTeamQuota(),
 		},
-		ConfigureContextFunc: configureContext,
+	}
+	p.ConfigureContextFunc = configureContext(p)
+
+	return p
+}
+
+func configureContext(p *schema.Provider) schema.ConfigureContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		return configure(ctx, d, userAgent(p.TerraformVersion, d.Get("user_agent_suffix").(string)))
+	}
+}
+
+// userAgent identifies Terraform traffic towards the API, e.g.
+// "terraform-provider-symbiosis/0.3 terraform/1.1.0".
+func userAgent(terraformVersion string, suffix string) string {
+	if terraformVersion == "" {
+		// Terraform before 0.12 does not report its version
+		terraformVersion = "0.11+compatible"
+	}
+
+	ua := fmt.Sprintf("terraform-provider-symbiosis/%s terraform/%s", ProviderVersion, terraformVersion)
+	if suffix != "" {
+		ua = ua + " " + suffix
+	}
+	return ua
+}
+
+func withUserAgent(ua string) symbiosis.ClientOption {
+	return func(c *resty.Client) {
+		c.SetHeader("User-Agent", ua)
 	}
 }
 
-func configureContext(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+func configure(ctx context.Context, d *schema.ResourceData, ua string) (interface{}, diag.Diagnostics) {
 
 	endpoint := d.Get("endpoint").(string)
 	apiKey := d.Get("api_key").(string)
 
-	c, err := symbiosis.NewClientFromAPIKey(apiKey, symbiosis.WithEndpoint(endpoint))
+	options := []symbiosis.ClientOption{symbiosis.WithEndpoint(endpoint), withUserAgent(ua)}
+
+	// Build the shared transport once, clients for each request context wrap it
+	var transport http.RoundTripper
+	_, err := symbiosis.NewClientFromAPIKey(apiKey, append(options, withHTTPLogging(), func(c *resty.Client) {
+		transport = c.GetClient().Transport
+	})...)
 
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
 
+	transport = newRateLimitTransport(transport, d.Get("max_concurrent_requests").(int), d.Get("requests_per_second").(float64))
+
+	// poll_interval is validated by the schema, so it always parses
+	pollInterval, _ := time.ParseDuration(d.Get("poll_interval").(string))
+
+	m := &providerMeta{
+		apiKey:       apiKey,
+		options:      options,
+		transport:    transport,
+		pollInterval: pollInterval,
+		clusterLocks: newClusterLocks(),
+		cache:        newAPICache(),
+		policy:       expandPolicy(d.Get("policy").([]interface{})),
+		quotaCheck:   d.Get("quota_check").(string),
+		planned:      newPlannedUsage(),
+		costs: costSettings{
+			currency:                  d.Get("cost_currency").(string),
+			controlPlaneMonthlyCost:   d.Get("control_plane_monthly_cost").(float64),
+			haControlPlaneMonthlyCost: d.Get("ha_control_plane_monthly_cost").(float64),
+		},
+	}
+
 	// Verify that api key is valid and has connectivity to API gateway
-	clusters, err := c.Cluster.List(10, 0)
+	client, err := m.client(ctx)
+	if err != nil {
+		return m, diag.FromErr(err)
+	}
+	clusters, err := client.Cluster.List(10, 0)
 	if err != nil {
-		return c, diag.FromErr(err)
+		return m, apiErrorDiags(err, "")
 	}
 	if clusters == nil {
-		return c, diag.FromErr(errors.New("Failed to read API result"))
+		return m, diag.FromErr(errors.New("Failed to read API result"))
 	}
 
 	var diags diag.Diagnostics
-	return c, diags
+	return m, diags
 }
@@ -0,0 +1,18 @@
+package symbiosis
+
+import (
+	"net/http"
+)
+
+// testProviderMeta returns provider state for tests that serve every API
+// response from the cache, so no requests are sent.
+func testProviderMeta() *providerMeta {
+	return &providerMeta{
+		transport:    http.DefaultTransport,
+		clusterLocks: newClusterLocks(),
+		cache:        newAPICache(),
+		policy:       &providerPolicy{},
+		quotaCheck:   "off",
+		planned:      newPlannedUsage(),
+	}
+}
@@ -0,0 +1,239 @@
+package symbiosis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// teamQuota holds the limits of the team owning the API key. A limit of 0 means
+// unlimited.
+type teamQuota struct {
+	MaxClusters int
+	MaxNodes    int
+	MaxVcpu     int
+}
+
+// teamUsage counts what the team owning the API key currently runs, or what a
+// plan adds to it.
+type teamUsage struct {
+	Clusters int
+	Nodes    int
+	Vcpu     int
+}
+
+// plannedUsage adds up what the clusters and node pools planned in one run add
+// to the team usage, so each plan is checked against the total instead of on
+// its own. Additions are kept by resource, since the SDK may diff a resource
+// more than once, and dropped once the resource is applied and its nodes show
+// up in the usage counted from the API.
+type plannedUsage struct {
+	mu        sync.Mutex
+	additions map[string]teamUsage
+}
+
+func newPlannedUsage() *plannedUsage {
+	return &plannedUsage{additions: map[string]teamUsage{}}
+}
+
+// add records the addition of the resource key and returns the total of all
+// planned additions.
+func (p *plannedUsage) add(key string, addition teamUsage) teamUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.additions[key] = addition
+
+	total := teamUsage{}
+	for _, a := range p.additions {
+		total.Clusters += a.Clusters
+		total.Nodes += a.Nodes
+		total.Vcpu += a.Vcpu
+	}
+	return total
+}
+
+func (p *plannedUsage) remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.additions, key)
+}
+
+func clusterQuotaKey(name string) string {
+	return "cluster/" + name
+}
+
+func nodePoolQuotaKey(clusterName, name string) string {
+	return "node_pool/" + clusterName + "/" + name
+}
+
+// fetchTeamQuota returns the quota of the team. symbiosis-go has no endpoint
+// for it yet, so no limits are known and every check passes. Fetch the quota
+// here once the API reports it.
+func (m *providerMeta) fetchTeamQuota(ctx context.Context) (*teamQuota, error) {
+	log.Printf("[DEBUG] The API does not report team quotas, quota checks pass")
+	return &teamQuota{}, nil
+}
+
+// fetchTeamUsage adds up the clusters and node pools of the team. Node pools
+// count with their desired quantity.
+func (m *providerMeta) fetchTeamUsage(ctx context.Context) (*teamUsage, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usage := &teamUsage{}
+
+	for page := 0; ; page++ {
+		list, err := client.Cluster.List(clusterListPageSize, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cluster := range list.Clusters {
+			usage.Clusters++
+
+			nodePools := cluster.NodePools
+			if nodePools == nil {
+				described, err := m.cache.describeCluster(ctx, m, cluster.Name)
+				if err != nil {
+					return nil, err
+				}
+				nodePools = described.NodePools
+			}
+
+			for _, nodePool := range nodePools {
+				usage.Nodes += nodePool.DesiredQuantity
+				usage.Vcpu += nodePool.DesiredQuantity * m.nodeTypeVcpu(ctx, nodePool.NodeTypeName)
+			}
+		}
+
+		if len(list.Clusters) < clusterListPageSize {
+			return usage, nil
+		}
+	}
+}
+
+// checkQuota records the addition planned for the resource key and checks the
+// team usage plus all planned additions against the quota. A violation fails
+// the plan or, when quota_check is "warn", is returned for the quota_warning
+// attribute.
+func (m *providerMeta) checkQuota(ctx context.Context, key string, addition teamUsage) (string, error) {
+	if m.quotaCheck == "off" {
+		return "", nil
+	}
+
+	quota, err := m.cache.teamQuota(ctx, m)
+	if err != nil {
+		return "", err
+	}
+	if *quota == (teamQuota{}) {
+		return "", nil
+	}
+
+	usage, err := m.cache.teamUsage(ctx, m)
+	if err != nil {
+		return "", err
+	}
+	planned := m.planned.add(key, addition)
+
+	err = quota.check(usage, addition, planned)
+	if err != nil && m.quotaCheck == "warn" {
+		log.Printf("[WARN] %s", err)
+		return err.Error(), nil
+	}
+	return "", err
+}
+
+// check fails when the usage plus the planned additions exceed a limit the
+// addition of this resource counts against.
+func (q *teamQuota) check(usage *teamUsage, addition, planned teamUsage) error {
+	if q.MaxClusters > 0 && addition.Clusters > 0 && usage.Clusters+planned.Clusters > q.MaxClusters {
+		return fmt.Errorf("quota exceeded: the plan adds %d clusters but the team already uses %d of %d clusters", planned.Clusters, usage.Clusters, q.MaxClusters)
+	}
+	if q.MaxNodes > 0 && addition.Nodes > 0 && usage.Nodes+planned.Nodes > q.MaxNodes {
+		return fmt.Errorf("quota exceeded: the plan adds %d nodes but the team already uses %d of %d nodes", planned.Nodes, usage.Nodes, q.MaxNodes)
+	}
+	if q.MaxVcpu > 0 && addition.Vcpu > 0 && usage.Vcpu+planned.Vcpu > q.MaxVcpu {
+		return fmt.Errorf("quota exceeded: the plan adds %d vCPUs but the team already uses %d of %d vCPUs", planned.Vcpu, usage.Vcpu, q.MaxVcpu)
+	}
+	return nil
+}
+
+// checkClusterQuota checks one more cluster against max_clusters.
+func checkClusterQuota(ctx context.Context, d *schema.ResourceDiff, m *providerMeta) (string, error) {
+	return m.checkQuota(ctx, clusterQuotaKey(d.Get("name").(string)), teamUsage{Clusters: 1})
+}
+
+// checkNodePoolQuota checks the nodes and vCPUs the planned change adds,
+// together with the other clusters and node pools planned in the same run.
+func checkNodePoolQuota(ctx context.Context, d *schema.ResourceDiff, m *providerMeta) (string, error) {
+	if m.quotaCheck == "off" {
+		return "", nil
+	}
+	if !d.NewValueKnown("node_type") || !d.NewValueKnown("quantity") || !d.NewValueKnown("autoscaling") {
+		return "", nil
+	}
+	if d.Id() != "" && !d.HasChange("node_type") && !d.HasChange("quantity") && !d.HasChange("autoscaling") {
+		return "", nil
+	}
+
+	newNodes := plannedNodePoolSize(d)
+	newVcpu := m.nodeTypeVcpu(ctx, d.Get("node_type").(string))
+
+	addedNodes, addedVcpu := newNodes, newNodes*newVcpu
+	// A surge replacement keeps the old pool until the new one is complete
+	if d.Id() != "" && !(d.HasChange("node_type") && d.Get("replacement_strategy").(string) == "surge") {
+		o, _ := d.GetChange("node_type")
+		oldVcpu := m.nodeTypeVcpu(ctx, o.(string))
+		// current_quantity is planned as computed when the size changes
+		o, _ = d.GetChange("current_quantity")
+		oldNodes := o.(int)
+		addedNodes -= oldNodes
+		addedVcpu -= oldNodes * oldVcpu
+	}
+
+	// A shrinking pool may only be applied after the others grow, so it frees
+	// nothing for them
+	addition := teamUsage{}
+	if addedNodes > 0 {
+		addition.Nodes = addedNodes
+	}
+	if addedVcpu > 0 {
+		addition.Vcpu = addedVcpu
+	}
+
+	return m.checkQuota(ctx, nodePoolQuotaKey(d.Get("cluster").(string), d.Get("name").(string)), addition)
+}
+
+// nodeTypeVcpu returns the vCPUs of a node type, or 0 when it cannot be
+// looked up so that only the node count is checked.
+func (m *providerMeta) nodeTypeVcpu(ctx context.Context, nodeTypeName string) int {
+	nodeTypes, err := m.cache.listNodeTypes(ctx, m)
+	if err != nil {
+		log.Printf("[WARN] Cannot list node types, skipping vCPU quota check: %s", err)
+		return 0
+	}
+
+	for _, nodeType := range nodeTypes {
+		if nodeType.Name == nodeTypeName {
+			return nodeType.Vcpu
+		}
+	}
+
+	log.Printf("[WARN] Unknown node type %s, skipping vCPU quota check", nodeTypeName)
+	return 0
+}
+
+// setQuotaWarning shows a quota violation tolerated by quota_check "warn" in
+// the plan.
+func setQuotaWarning(d *schema.ResourceDiff, warning string) error {
+	if warning == "" {
+		return nil
+	}
+	return d.SetNew("quota_warning", warning)
+}
@@ -0,0 +1,51 @@
+package symbiosis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+func TestCheckNodePoolQuotaPlannedTotals(t *testing.T) {
+	m := testProviderMeta()
+	m.quotaCheck = "error"
+	m.cache.quota = &teamQuota{MaxNodes: 10}
+	m.cache.usage = &teamUsage{Clusters: 1, Nodes: 3, Vcpu: 6}
+	m.cache.clusters["prod"] = &symbiosis.Cluster{Name: "prod", KubeVersion: "1.24.3"}
+	m.cache.nodeTypes = []*symbiosis.NodeType{{Name: "general-1", Vcpu: 2}}
+
+	config := func(name string, quantity int) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":      name,
+			"cluster":   "prod",
+			"node_type": "general-1",
+			"quantity":  quantity,
+		})
+	}
+	diff := func(name string, quantity int) error {
+		_, err := ResourceNodePool().Diff(context.Background(), nil, config(name, quantity), m)
+		return err
+	}
+
+	if err := diff("web", 4); err != nil {
+		t.Fatalf("first pool: unexpected error: %s", err)
+	}
+	// Diffing the same pool again replaces its planned addition
+	if err := diff("web", 4); err != nil {
+		t.Fatalf("first pool again: unexpected error: %s", err)
+	}
+
+	err := diff("batch", 4)
+	if err == nil || !strings.Contains(err.Error(), "the plan adds 8 nodes but the team already uses 3 of 10 nodes") {
+		t.Fatalf("second pool: expected quota error, got %v", err)
+	}
+
+	// Once applied, the first pool counts through the usage instead
+	m.planned.remove(nodePoolQuotaKey("prod", "web"))
+	if err := diff("batch", 3); err != nil {
+		t.Fatalf("second pool after apply: unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,111 @@
+package symbiosis
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitTransport caps the number of API requests in flight and spaces
+// requests so no more than requestsPerSecond are sent. It is shared by every
+// client of a provider instance, so the limits hold across resources and data
+// sources refreshed in parallel.
+type rateLimitTransport struct {
+	next http.RoundTripper
+
+	// slots holds one token per request in flight, nil when unlimited
+	slots chan struct{}
+
+	interval time.Duration
+	mu       sync.Mutex
+	nextSend time.Time
+}
+
+// newRateLimitTransport wraps next unless both limits are zero, which means unlimited.
+func newRateLimitTransport(next http.RoundTripper, maxConcurrent int, requestsPerSecond float64) http.RoundTripper {
+	if maxConcurrent <= 0 && requestsPerSecond <= 0 {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	t := &rateLimitTransport{next: next}
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+	if requestsPerSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return t
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if wait := t.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			t.release()
+			return nil, ctx.Err()
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		t.release()
+		return resp, err
+	}
+
+	// Keep the slot until the body has been read
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: t.release}
+	return resp, nil
+}
+
+// reserve returns how long the caller has to wait for its turn to send.
+func (t *rateLimitTransport) reserve() time.Duration {
+	if t.interval == 0 {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.nextSend.Before(now) {
+		t.nextSend = now
+	}
+	wait := t.nextSend.Sub(now)
+	t.nextSend = t.nextSend.Add(t.interval)
+	return wait
+}
+
+func (t *rateLimitTransport) release() {
+	if t.slots != nil {
+		<-t.slots
+	}
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func ResourceCluster() *schema.Resource {
@@ -21,10 +22,12 @@ func ResourceCluster() *schema.Resource {
     `,
 		CreateContext: resourceClusterCreate,
 		ReadContext:   resourceClusterRead,
+		UpdateContext: resourceClusterUpdate,
 		DeleteContext: resourceClusterDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceClusterImport,
 		},
+		SchemaVersion: 0,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -51,6 +54,25 @@ func ResourceCluster() *schema.Resource {
 				Default:     false,
 				Description: "When set to true it will deploy a highly available control plane with multiple replicas for redundancy.",
 			},
+			"on_create_failure": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "keep",
+				ValidateFunc: validation.StringInSlice([]string{"keep", "delete"}, false),
+				Description:  "What to do with a cluster whose creation ends in state FAILED. \"keep\" leaves it for inspection, \"delete\" removes it so the next apply can retry cleanly.",
+			},
+			"adopt_existing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When set to true and a cluster with the same name already exists in the same region, it is taken over into state instead of failing the create.",
+			},
+			"delete_node_pools": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When set to true all node pools in the cluster are deleted, and their removal awaited, before the cluster itself is deleted.",
+			},
 			"state": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -76,6 +98,26 @@ func ResourceCluster() *schema.Resource {
 				Computed:  true,
 				Sensitive: true,
 			},
+			"creation_pending": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True while the cluster has not become ACTIVE after an apply was cancelled during its creation. Refreshing the cluster resumes waiting for it and fails while it is still not ACTIVE, so endpoint and credentials, which may be empty until then, are not used. A create that times out fails the apply instead.",
+			},
+			"estimated_monthly_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Estimated monthly price of the control plane and all node pools of the cluster as of the last refresh, in the provider cost_currency. Node pools are priced at their largest size.",
+			},
+			"replacement_warning": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Set in plans that replace the cluster, listing the node pools and service accounts destroyed with it.",
+			},
+			"quota_warning": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Set in plans creating the cluster while the provider quota_check is \"warn\" and the planned clusters exceed the team quota.",
+			},
 			"kubeconfig": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -83,8 +125,11 @@ func ResourceCluster() *schema.Resource {
 				Description: "The raw kubeconfig file.",
 			},
 		},
+		CustomizeDiff: resourceClusterCustomizeDiff,
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 	}
 }
@@ -92,7 +137,18 @@ func ResourceCluster() *schema.Resource {
 func resourceClusterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating cluster: %s", d.Get("name").(string))
 
-	client := meta.(*symbiosis.Client)
+	m := meta.(*providerMeta)
+	client, err := m.client(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	unlock, err := m.lockCluster(ctx, d.Get("name").(string))
+	if err != nil {
+		return apiErrorDiags(err, nameCluster)
+	}
+	defer unlock()
+	defer m.planned.remove(clusterQuotaKey(d.Get("name").(string)))
 
 	input := &symbiosis.ClusterInput{
 		Name:              d.Get("name").(string),
@@ -102,20 +158,72 @@ func resourceClusterCreate(ctx context.Context, d *schema.ResourceData, meta int
 		IsHighlyAvailable: d.Get("is_highly_available").(bool),
 	}
 
-	cluster, err := client.Cluster.Create(input)
+	var cluster *symbiosis.Cluster
+	err = createIdempotently(ctx, m, d.Timeout(schema.TimeoutCreate), func(c *symbiosis.Client) (err error) {
+		cluster, err = c.Cluster.Create(input)
+		return err
+	}, func(c *symbiosis.Client) (bool, error) {
+		existing, err := c.Cluster.Describe(input.Name)
+		if isNotFoundError(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		cluster = existing
+		return true, nil
+	})
 
+	if isConflictError(err) && d.Get("adopt_existing").(bool) {
+		cluster, err = adoptExistingCluster(client, input)
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameCluster)
 	}
 
 	d.SetId(cluster.Name)
 	d.Set("name", cluster.Name)
+	d.Set("state", cluster.State)
+	// Cleared once the cluster is ACTIVE, so an interrupted create resumes on the next refresh
+	d.Set("creation_pending", true)
 
-	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
-		c, err := client.Cluster.Describe(cluster.Name)
+	failed, err := waitForClusterActive(ctx, m, client, cluster.Name, d.Timeout(schema.TimeoutCreate))
+	if failed {
+		return handleClusterCreateFailure(ctx, d, m, err)
+	}
+	// Only a cancelled apply keeps the cluster untainted. Terraform stops there,
+	// so nothing runs against the credentials missing until the cluster is
+	// ACTIVE, whereas a timeout fails the apply as before.
+	if err != nil && ctx.Err() == context.Canceled {
+		setAvailableClusterDetails(d, m, cluster.Name)
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Cluster creation still in progress",
+			Detail:   fmt.Sprintf("Stopped waiting for cluster %s to become ACTIVE: %s. The cluster is kept in state and the next plan or apply resumes waiting for it. Its endpoint and credentials are only stored once it is ACTIVE.", cluster.Name, err),
+		}}
+	}
+	if err != nil {
+		return apiErrorDiags(err, nameCluster)
+	}
+
+	d.Set("creation_pending", false)
+
+	return resourceClusterRead(ctx, d, meta)
+}
+
+// waitForClusterActive waits for the cluster to become ACTIVE. failed is true
+// when the cluster ended up in state FAILED instead.
+func waitForClusterActive(ctx context.Context, m *providerMeta, client *symbiosis.Client, name string, timeout time.Duration) (failed bool, err error) {
+	err = m.retry(ctx, timeout, func() *resource.RetryError {
+		c, err := client.Cluster.Describe(name)
 
 		if err != nil {
-			return resource.NonRetryableError(fmt.Errorf("Error describing cluster: %s", err))
+			return resource.NonRetryableError(fmt.Errorf("Error describing cluster: %w", err))
+		}
+
+		if c.State == "FAILED" {
+			failed = true
+			return resource.NonRetryableError(clusterFailedError(c))
 		}
 
 		if c.State != "ACTIVE" {
@@ -124,29 +232,238 @@ func resourceClusterCreate(ctx context.Context, d *schema.ResourceData, meta int
 
 		return nil
 	})
+
+	return failed, err
+}
+
+// isWaitInterrupted reports whether a wait ended because it timed out or was
+// cancelled rather than because of an API error.
+func isWaitInterrupted(ctx context.Context, err error) bool {
+	if _, ok := err.(*resource.TimeoutError); ok {
+		return true
+	}
+	return ctx.Err() != nil
+}
+
+// clusterReplacingAttributes are the ForceNew attributes of a cluster.
+var clusterReplacingAttributes = []string{"name", "kube_version", "region", "is_highly_available"}
+
+// resourceClusterCustomizeDiff enforces the provider policy and team quota, replaces clusters
+// whose interrupted creation ended in state FAILED, and describes what a planned replacement destroys in
+// replacement_warning.
+func resourceClusterCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	err := meta.(*providerMeta).policy.checkCluster(d)
+	if err != nil {
+		return err
+	}
+
+	m := meta.(*providerMeta)
+
+	if d.Id() == "" {
+		// When a cluster is replaced the SDK diffs it a second time without
+		// state and keeps only that diff, so the warning of the first pass has
+		// to be set again
+		if warning, ok := m.replacementWarnings.LoadAndDelete(d.Get("name").(string)); ok {
+			err = d.SetNew("replacement_warning", warning.(string))
+			if err != nil {
+				return err
+			}
+		} else {
+			// A replaced cluster is destroyed before its successor is created,
+			// so only new clusters count against the quota
+			warning, err := checkClusterQuota(ctx, d, m)
+			if err != nil {
+				return err
+			}
+			err = setQuotaWarning(d, warning)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !d.NewValueKnown("is_highly_available") {
+			return nil
+		}
+		// A new cluster has no node pools yet
+		return d.SetNew("estimated_monthly_cost", m.controlPlaneMonthlyCost(d.Get("is_highly_available").(bool)))
+	}
+
+	var reasons []string
+
+	if d.Get("creation_pending").(bool) && d.Get("state").(string) == "FAILED" {
+		log.Printf("[WARN] Cluster %s failed during creation, planning replacement", d.Id())
+		err = d.SetNew("creation_pending", false)
+		if err != nil {
+			return err
+		}
+		err = d.ForceNew("creation_pending")
+		if err != nil {
+			return err
+		}
+		reasons = append(reasons, "it failed during creation")
+	}
+
+	for _, key := range clusterReplacingAttributes {
+		if d.HasChange(key) {
+			o, n := d.GetChange(key)
+			reasons = append(reasons, fmt.Sprintf("%s changes from %v to %v", key, o, n))
+		}
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	warning := clusterReplacementWarning(ctx, d.Id(), m, reasons)
+	log.Printf("[WARN] %s", warning)
+	if d.NewValueKnown("name") {
+		m.replacementWarnings.Store(d.Get("name").(string), warning)
+	}
+	return d.SetNew("replacement_warning", warning)
+}
+
+// clusterReplacementWarning lists the node pools destroyed with the cluster.
+// Service accounts cannot be listed through the API, so they are only
+// mentioned.
+func clusterReplacementWarning(ctx context.Context, name string, m *providerMeta, reasons []string) string {
+	pools := "all of its node pools"
+
+	cluster, err := m.cache.describeCluster(ctx, m, name)
+	if err != nil {
+		log.Printf("[WARN] Cannot list node pools of cluster %s: %s", name, err)
+	} else if len(cluster.NodePools) == 0 {
+		pools = "no node pools"
+	} else {
+		names := make([]string, 0, len(cluster.NodePools))
+		for _, nodePool := range cluster.NodePools {
+			names = append(names, nodePool.Name)
+		}
+		pools = fmt.Sprintf("its node pools %s", strings.Join(names, ", "))
+	}
+
+	return fmt.Sprintf("Cluster %s will be replaced because %s. This destroys %s, every service account issued for it and all workloads running on it.", name, strings.Join(reasons, " and "), pools)
+}
+
+// adoptExistingCluster takes over a cluster that already exists with the
+// requested name, e.g. one left behind by an interrupted apply.
+func adoptExistingCluster(client *symbiosis.Client, input *symbiosis.ClusterInput) (*symbiosis.Cluster, error) {
+	cluster, err := client.Cluster.Describe(input.Name)
+	if err != nil {
+		return nil, fmt.Errorf("Error describing existing cluster %s: %w", input.Name, err)
+	}
+
+	if cluster.Region == nil || cluster.Region.Name != input.Region {
+		return nil, fmt.Errorf("Cannot adopt existing cluster %s: it is in a different region than %s", input.Name, input.Region)
+	}
+
+	log.Printf("[INFO] Adopting existing cluster: %s", cluster.Name)
+	return cluster, nil
+}
+
+// clusterFailedError describes a FAILED cluster. The API reports no reason for
+// the failure, so the error identifies the cluster for a support request.
+func clusterFailedError(c *symbiosis.Cluster) error {
+	region := ""
+	if c.Region != nil {
+		region = c.Region.Name
+	}
+	return fmt.Errorf("cluster %s (ID %s, Kubernetes %s, region %s) entered state FAILED. The Symbiosis API does not report why, contact Symbiosis support with the cluster ID for details", c.Name, c.ID, c.KubeVersion, region)
+}
+
+// handleClusterCreateFailure reports a cluster that ended up FAILED and, with
+// on_create_failure = "delete", removes it so the next apply starts over.
+func handleClusterCreateFailure(ctx context.Context, d *schema.ResourceData, m *providerMeta, cause error) diag.Diagnostics {
+	client, err := m.client(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	if d.Get("on_create_failure").(string) != "delete" {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Cluster creation failed",
+			Detail:   fmt.Sprintf("%s. The cluster was kept for inspection and will be replaced on the next apply, set on_create_failure = \"delete\" to remove it automatically.", cause),
+		}}
+	}
+
+	log.Printf("[DEBUG] Deleting failed cluster: %s", d.Id())
+	err = client.Cluster.Delete(d.Id())
+	if err != nil && !isNotFoundError(err) {
+		return diag.Errorf("%s and deleting it failed: %s", cause, err)
+	}
+
+	err = waitForClusterDeleted(ctx, m, client, d.Id(), d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return diag.Errorf("%s and waiting for its deletion failed: %s", cause, err)
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  "Cluster creation failed",
+		Detail:   fmt.Sprintf("%s. The failed cluster was deleted, the next apply creates it again.", cause),
+	}}
+}
+
+func resourceClusterImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	d.Set("delete_node_pools", false)
+	d.Set("on_create_failure", "keep")
+	d.Set("adopt_existing", false)
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceClusterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Only provider-side settings such as delete_node_pools and on_create_failure can change in place,
+	// so there is nothing to send to the API.
 	return resourceClusterRead(ctx, d, meta)
 }
 
 func resourceClusterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Deleting cluster: %s", d.Id())
-	client := meta.(*symbiosis.Client)
-
-	err := client.Cluster.Delete(d.Id())
+	m := meta.(*providerMeta)
+	client, err := m.client(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	unlock, err := m.lockCluster(ctx, d.Id())
+	if err != nil {
+		return apiErrorDiags(err, nameCluster)
+	}
+	defer unlock()
+
+	if d.Get("delete_node_pools").(bool) {
+		err := deleteClusterNodePools(ctx, d, m)
+		if err != nil {
+			return apiErrorDiags(err, nameCluster)
+		}
+	}
+
+	err = client.Cluster.Delete(d.Id())
+	if isNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return apiErrorDiags(err, nameCluster)
+	}
+
 	var diags diag.Diagnostics
 
-	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
-		c, err := client.Cluster.Describe(d.Id())
+	err = waitForClusterDeleted(ctx, m, client, d.Id(), d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return apiErrorDiags(err, nameCluster)
+	}
+
+	return diags
+}
+
+func waitForClusterDeleted(ctx context.Context, m *providerMeta, client *symbiosis.Client, name string, timeout time.Duration) error {
+	return m.retry(ctx, timeout, func() *resource.RetryError {
+		c, err := client.Cluster.Describe(name)
 
-		if err != nil && !strings.Contains(err.Error(), "404") {
-			return resource.NonRetryableError(fmt.Errorf("Error describing cluster: %s", err))
+		if err != nil && !isNotFoundError(err) {
+			return resource.NonRetryableError(fmt.Errorf("Error describing cluster: %w", err))
 		}
 
 		if c != nil {
@@ -155,45 +472,170 @@ func resourceClusterDelete(ctx context.Context, d *schema.ResourceData, meta int
 
 		return nil
 	})
+}
+
+// deleteClusterNodePools deletes every node pool of the cluster and waits for
+// them to disappear, so the cluster is torn down only once it is empty.
+func deleteClusterNodePools(ctx context.Context, d *schema.ResourceData, m *providerMeta) error {
+	client, err := m.client(ctx)
 	if err != nil {
-		return diag.FromErr(err)
+		return err
 	}
 
-	return diags
+	cluster, err := client.Cluster.Describe(d.Id())
+	if isNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, nodePool := range cluster.NodePools {
+		log.Printf("[DEBUG] Deleting node pool %s of cluster %s", nodePool.ID, cluster.Name)
+		err := client.NodePool.Delete(nodePool.ID)
+		if err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("Error deleting node pool %s: %w", nodePool.Name, err)
+		}
+	}
+
+	return m.retry(ctx, d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		for _, nodePool := range cluster.NodePools {
+			_, err := client.NodePool.Describe(nodePool.ID)
+			if isNotFoundError(err) {
+				continue
+			}
+			if err != nil {
+				return resource.NonRetryableError(fmt.Errorf("Error describing node pool: %w", err))
+			}
+			return resource.RetryableError(fmt.Errorf("expected node pool %s to get removed but it is still returned from api", nodePool.Name))
+		}
+
+		return nil
+	})
 }
 
 func resourceClusterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading cluster: %s", d.Id())
-	client := meta.(*symbiosis.Client)
+	m := meta.(*providerMeta)
 
-	cluster, err := client.Cluster.Describe(d.Id())
-	if err != nil && !strings.Contains(err.Error(), "404") {
-		return diag.FromErr(err)
+	cluster, err := m.cache.describeCluster(ctx, m, d.Id())
+	if err != nil && !isNotFoundError(err) {
+		return apiErrorDiags(err, nameCluster)
 	}
 
 	log.Printf("[DEBUG] Cluster resource: %v", cluster)
 
-	identity, err := client.Cluster.GetIdentity(d.Id())
+	if cluster == nil {
+		log.Printf("[WARN] Cluster %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
 
-	if err != nil {
-		return diag.FromErr(err)
+	if d.Get("creation_pending").(bool) && cluster.State != "ACTIVE" {
+		diags := resumeClusterCreation(ctx, d, m, cluster)
+		if diags != nil {
+			return diags
+		}
+
+		// The cluster changed state while waiting
+		m.cache.invalidateCluster(d.Id())
+		cluster, err = m.cache.describeCluster(ctx, m, d.Id())
+		if err != nil {
+			return apiErrorDiags(err, nameCluster)
+		}
 	}
 
-	if cluster != nil {
-		d.Set("name", cluster.Name)
-		d.Set("state", cluster.State)
-		d.Set("endpoint", cluster.APIServerEndpoint)
-		d.Set("region", cluster.Region.Name)
-		d.Set("is_highly_available", cluster.IsHighlyAvailable)
-		d.Set("certificate", identity.CertificatePem)
-		d.Set("ca_certificate", identity.ClusterCertificateAuthorityPem)
-		d.Set("private_key", identity.PrivateKeyPem)
-		d.Set("kubeconfig", identity.KubeConfig)
+	identity, err := m.cache.clusterIdentity(ctx, m, d.Id())
 
-	} else {
-		d.SetId("")
+	if err != nil {
+		return apiErrorDiags(err, nameCluster)
+	}
+
+	d.Set("name", cluster.Name)
+	d.Set("state", cluster.State)
+	d.Set("endpoint", cluster.APIServerEndpoint)
+	d.Set("region", cluster.Region.Name)
+	d.Set("is_highly_available", cluster.IsHighlyAvailable)
+	d.Set("certificate", identity.CertificatePem)
+	d.Set("ca_certificate", identity.ClusterCertificateAuthorityPem)
+	d.Set("private_key", identity.PrivateKeyPem)
+	d.Set("kubeconfig", identity.KubeConfig)
+	d.Set("creation_pending", false)
+	d.Set("estimated_monthly_cost", m.clusterMonthlyCost(ctx, cluster))
+	// Keep the warning of the plan that created this cluster until the next refresh
+	if !d.IsNewResource() {
+		d.Set("replacement_warning", "")
+		d.Set("quota_warning", "")
 	}
 
 	var diags diag.Diagnostics
 	return diags
 }
+
+// resumeClusterCreation continues waiting for a cluster whose create was
+// interrupted. It returns nil once the cluster is ACTIVE, otherwise the
+// diagnostics to report while the cluster stays pending in state.
+func resumeClusterCreation(ctx context.Context, d *schema.ResourceData, m *providerMeta, cluster *symbiosis.Cluster) diag.Diagnostics {
+	log.Printf("[INFO] Resuming creation of cluster %s in state %s", cluster.Name, cluster.State)
+
+	client, err := m.client(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	failed, err := waitForClusterActive(ctx, m, client, cluster.Name, d.Timeout(schema.TimeoutCreate))
+	if err == nil {
+		return nil
+	}
+
+	if failed {
+		d.Set("state", "FAILED")
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Cluster creation failed",
+			Detail:   fmt.Sprintf("%s. The cluster will be replaced on the next apply.", err),
+		}}
+	}
+
+	if isWaitInterrupted(ctx, err) {
+		// An error keeps the refresh from handing out the missing credentials
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Cluster creation still in progress",
+			Detail:   fmt.Sprintf("Stopped waiting for cluster %s to become ACTIVE: %s. Run Terraform again to keep waiting for it.", cluster.Name, err),
+		}}
+	}
+
+	return apiErrorDiags(err, nameCluster)
+}
+
+// setAvailableClusterDetails stores the endpoint and identity of a cluster
+// that is not ACTIVE yet as far as the API already returns them. It uses its
+// own short deadline as the operation context is already cancelled.
+func setAvailableClusterDetails(d *schema.ResourceData, m *providerMeta, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := m.client(ctx)
+	if err != nil {
+		log.Printf("[WARN] Cannot describe pending cluster %s: %s", name, err)
+		return
+	}
+
+	cluster, err := client.Cluster.Describe(name)
+	if err != nil {
+		log.Printf("[WARN] Cannot describe pending cluster %s: %s", name, err)
+		return
+	}
+	d.Set("state", cluster.State)
+	d.Set("endpoint", cluster.APIServerEndpoint)
+
+	identity, err := client.Cluster.GetIdentity(name)
+	if err != nil {
+		log.Printf("[WARN] Cannot read identity of pending cluster %s: %s", name, err)
+		return
+	}
+	d.Set("certificate", identity.CertificatePem)
+	d.Set("ca_certificate", identity.ClusterCertificateAuthorityPem)
+	d.Set("private_key", identity.PrivateKeyPem)
+	d.Set("kubeconfig", identity.KubeConfig)
+}
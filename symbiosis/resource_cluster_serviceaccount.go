@@ -5,8 +5,6 @@ import (
 	"log"
 	"time"
 
-	"github.com/symbiosis-cloud/symbiosis-go"
-
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -19,6 +17,7 @@ func ResourceClusterServiceAccount() *schema.Resource {
 		CreateContext: resourceClusterServiceAccountCreate,
 		ReadContext:   resourceClusterServiceAccountRead,
 		DeleteContext: resourceClusterServiceAccountDelete,
+		SchemaVersion: 0,
 		Schema: map[string]*schema.Schema{
 			"cluster_name": {
 				Type:        schema.TypeString,
@@ -41,6 +40,7 @@ func ResourceClusterServiceAccount() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 	}
 }
@@ -49,12 +49,15 @@ func resourceClusterServiceAccountCreate(ctx context.Context, d *schema.Resource
 	log.Printf("[DEBUG] Creating service account: %s", d.Get("cluster_name").(string))
 	var diags diag.Diagnostics
 	clusterName := d.Get("cluster_name").(string)
-	client := meta.(*symbiosis.Client)
+	client, err := meta.(*providerMeta).client(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	serviceaccount, err := client.Cluster.CreateServiceAccountForSelf(clusterName)
 
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameClusterServiceAccount)
 	}
 
 	d.SetId(serviceaccount.ID)
@@ -64,13 +67,17 @@ func resourceClusterServiceAccountCreate(ctx context.Context, d *schema.Resource
 
 func resourceClusterServiceAccountDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Deleting service account: %s", d.Id())
-	client := meta.(*symbiosis.Client)
-	clusterName := d.Get("cluster_name").(string)
-
-	err := client.Cluster.DeleteServiceAccount(clusterName, d.Id())
+	client, err := meta.(*providerMeta).client(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	clusterName := d.Get("cluster_name").(string)
+
+	// The service account is already gone when its cluster was deleted first
+	err = client.Cluster.DeleteServiceAccount(clusterName, d.Id())
+	if err != nil && !isNotFoundError(err) {
+		return apiErrorDiags(err, nameClusterServiceAccount)
+	}
 
 	var diags diag.Diagnostics
 
@@ -79,12 +86,15 @@ func resourceClusterServiceAccountDelete(ctx context.Context, d *schema.Resource
 
 func resourceClusterServiceAccountRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading service account: %s", d.Id())
-	client := meta.(*symbiosis.Client)
+	client, err := meta.(*providerMeta).client(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	clusterName := d.Get("cluster_name").(string)
 
 	serviceAccount, err := client.Cluster.GetServiceAccount(clusterName, d.Id())
-	if err != nil {
-		return diag.FromErr(err)
+	if err != nil && !isNotFoundError(err) {
+		return apiErrorDiags(err, nameClusterServiceAccount)
 	}
 	if serviceAccount != nil {
 		d.Set("cluster_certificate_authority", serviceAccount.ClusterCertificateAuthority)
@@ -92,6 +102,7 @@ func resourceClusterServiceAccountRead(ctx context.Context, d *schema.ResourceDa
 		d.Set("token", serviceAccount.ServiceAccountToken)
 		d.Set("cluster_ca_certificate", serviceAccount.ClusterCertificateAuthority)
 	} else {
+		log.Printf("[WARN] Service account %s not found, removing from state", d.Id())
 		d.SetId("")
 	}
 
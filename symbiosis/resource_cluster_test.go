@@ -0,0 +1,63 @@
+package symbiosis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+func TestResourceClusterDiffReplacementWarning(t *testing.T) {
+	m := testProviderMeta()
+	// Served from the cache, so the diff makes no API calls
+	m.cache.clusters["old"] = &symbiosis.Cluster{
+		Name: "old",
+		NodePools: []*symbiosis.NodePool{
+			{ID: "pool-1", Name: "web"},
+			{ID: "pool-2", Name: "workers"},
+		},
+	}
+
+	state := &terraform.InstanceState{
+		ID: "old",
+		Attributes: map[string]string{
+			"id":                  "old",
+			"name":                "old",
+			"region":              "germany-1",
+			"kube_version":        "latest",
+			"is_highly_available": "false",
+			"on_create_failure":   "keep",
+			"adopt_existing":      "false",
+			"delete_node_pools":   "false",
+			"state":               "ACTIVE",
+			"creation_pending":    "false",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":   "new",
+		"region": "germany-1",
+	})
+
+	diff, err := ResourceCluster().Diff(context.Background(), state, config, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Fatalf("expected the name change to replace the cluster, got %#v", diff)
+	}
+
+	attr, ok := diff.Attributes["replacement_warning"]
+	if !ok {
+		t.Fatal("expected a diff for replacement_warning")
+	}
+	if attr.NewComputed {
+		t.Fatal("expected replacement_warning to be known in the plan")
+	}
+	for _, want := range []string{"Cluster old will be replaced", "name changes from old to new", "web, workers", "service account"} {
+		if !strings.Contains(attr.New, want) {
+			t.Errorf("expected replacement_warning %q to contain %q", attr.New, want)
+		}
+	}
+}
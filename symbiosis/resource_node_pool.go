@@ -3,12 +3,15 @@ package symbiosis
 import (
 	"context"
 	"fmt"
-
 	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/symbiosis-cloud/symbiosis-go"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -35,14 +38,26 @@ func ResourceNodePool() *schema.Resource {
 		},
 		"node_type": {
 			Type:        schema.TypeString,
-			ForceNew:    true,
 			Required:    true,
-			Description: "Type of nodes for this specific pool, see docs.",
+			Description: "Type of nodes for this specific pool, see docs. Changing the type forces re-creation unless replacement_strategy is \"surge\".",
 		},
 		"quantity": {
+			Type:             schema.TypeInt,
+			Description:      "Desired number of nodes for specific pool. Optional if autoscaling is enabled, in which case it is only used as the initial size.",
+			Optional:         true,
+			DiffSuppressFunc: suppressQuantityDiffWhenAutoscaling,
+		},
+		"current_quantity": {
 			Type:        schema.TypeInt,
-			Description: "Desired number of nodes for specific pool. Optional if autoscaling is enabled.",
-			Optional:    true,
+			Computed:    true,
+			Description: "Desired number of nodes as currently set in the API, including changes made by the autoscaler.",
+		},
+		"kube_version": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Computed:         true,
+			DiffSuppressFunc: suppressKubeVersionDiff,
+			Description:      "Kubernetes version of the nodes in this pool. Defaults to the cluster version. Changing it upgrades the pool in place by recycling its nodes one at a time. Versions are compared at the precision configured, so \"1.24\" matches a pool running 1.24.3.",
 		},
 		"labels": {
 			Type:        schema.TypeMap,
@@ -54,24 +69,32 @@ func ResourceNodePool() *schema.Resource {
 			},
 		},
 		"taint": {
-			Type:        schema.TypeSet,
-			Description: "Node taints to be applied to the nodes",
+			Type:        schema.TypeList,
+			Description: "Node taints to be applied to the nodes. Their order is not significant.",
 			Optional:    true,
 			ForceNew:    true,
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
+					// ForceNew on the list only reacts to the number of taints
 					"key": {
-						Type:     schema.TypeString,
-						Required: true,
+						Type:             schema.TypeString,
+						Required:         true,
+						ForceNew:         true,
+						DiffSuppressFunc: suppressTaintReorder,
 					},
 					"value": {
-						Type:     schema.TypeString,
-						Required: true,
+						Type:             schema.TypeString,
+						Optional:         true,
+						Default:          "",
+						ForceNew:         true,
+						DiffSuppressFunc: suppressTaintReorder,
 					},
 					"effect": {
-						Type:        schema.TypeString,
-						Required:    true,
-						Description: "Taint effect. Can be either NoSchedule, PreferNoSchedule or NoExecute. See: https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration/",
+						Type:             schema.TypeString,
+						Required:         true,
+						ForceNew:         true,
+						DiffSuppressFunc: suppressTaintReorder,
+						Description:      "Taint effect. Can be either NoSchedule, PreferNoSchedule or NoExecute. See: https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration/",
 						ValidateFunc: validation.StringInSlice([]string{
 							string(symbiosis.EFFECT_NO_SCHEDULE),
 							string(symbiosis.EFFECT_NO_EXECUTE),
@@ -82,7 +105,7 @@ func ResourceNodePool() *schema.Resource {
 			},
 		},
 		"autoscaling": {
-			Type:     schema.TypeSet,
+			Type:     schema.TypeList,
 			ForceNew: false,
 			MaxItems: 1,
 			Optional: true,
@@ -105,6 +128,109 @@ func ResourceNodePool() *schema.Resource {
 				},
 			},
 		},
+		"observed_quantity": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Number of nodes currently present in the pool.",
+		},
+		"estimated_monthly_cost": {
+			Type:        schema.TypeFloat,
+			Computed:    true,
+			Description: "Estimated monthly price of the pool at its largest size, the autoscaling max_size when autoscaling is enabled, in the provider cost_currency.",
+		},
+		"quota_warning": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Set in plans adding nodes beyond the team quota while the provider quota_check is \"warn\".",
+		},
+		"nodes": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Nodes currently present in the pool.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"id": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "ID of node.",
+					},
+					"name": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "Name of node, which is also its Kubernetes node name.",
+					},
+					"state": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "Node state.",
+					},
+					"private_ip": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "Private IPv4 address of node.",
+					},
+					"public_ip": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "Public IPv4 address of node.",
+					},
+					"created_at": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "Creation time of node in RFC 3339 format.",
+					},
+				},
+			},
+		},
+		"adopt_existing": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "When set to true and a node pool with the same name and node type already exists in the cluster, it is taken over into state instead of failing the create.",
+		},
+		"replacement_strategy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "recreate",
+			ValidateFunc: validation.StringInSlice([]string{"recreate", "surge"}, false),
+			Description:  "How a node_type change is rolled out. \"recreate\" deletes the pool before creating the new one, \"surge\" creates a pool with the new type first and drains and deletes the old one once it is ready. As pool names are unique within a cluster, the new pool is named <name>-surge-<suffix> in the API while keeping its configured name in Terraform.",
+		},
+		"max_surge": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Maximum number of new nodes added at a time during a surge replacement. 0 adds all nodes at once.",
+		},
+		"drain": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "When set, nodes are cordoned and drained through the Kubernetes API before the pool is deleted or scaled down, honoring PodDisruptionBudgets.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"timeout": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      "5m",
+						ValidateFunc: validateDuration,
+						Description:  "Maximum time to wait for a single node to drain.",
+					},
+					"ignore_daemonsets": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     true,
+						Description: "Skip pods managed by a DaemonSet instead of failing the drain.",
+					},
+					"delete_emptydir_data": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "Evict pods using emptyDir volumes, deleting their local data.",
+					},
+				},
+			},
+		},
 	}
 
 	return &schema.Resource{
@@ -114,71 +240,291 @@ func ResourceNodePool() *schema.Resource {
 		UpdateContext: resourceNodePoolUpdate,
 		DeleteContext: resourceNodePoolDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceNodePoolImport,
 		},
-		Schema: resourceSchema,
-		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, i interface{}) error {
-			autoscaling := expandAutoscalingSettings(d.Get("autoscaling").(*schema.Set).List())
-			if autoscaling.Enabled {
-				// quantity is optional when autoscaling is enabled
-				d.SetNew("quantity", 0)
-			} else {
-				quantity, ok := d.GetOk("quantity")
-				if !ok || quantity.(int) < 1 {
-					return fmt.Errorf("Quantity must be at least 1 if autoscaling is disabled")
-				}
-			}
-
-			return nil
+		Schema:        resourceSchema,
+		CustomizeDiff: resourceNodePoolCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceNodePoolV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceNodePoolStateUpgradeV0,
+			},
 		},
 	}
 }
 
-func resourceNodePoolCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	log.Printf("[DEBUG] Creating node pool with type %v for cluster %v", d.Get("node_type").(string), d.Id())
-	var diags diag.Diagnostics
+func resourceNodePoolImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Besides raw node pool IDs, pools can be imported as <cluster>/<pool_name>
+	if strings.Contains(d.Id(), "/") {
+		parts := strings.SplitN(d.Id(), "/", 2)
+		clusterName, poolName := parts[0], parts[1]
+		if clusterName == "" || poolName == "" {
+			return nil, fmt.Errorf("Unexpected import ID %q, expected <cluster>/<pool_name> or a node pool ID", d.Id())
+		}
 
-	client := meta.(*symbiosis.Client)
+		client, err := meta.(*providerMeta).client(ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	labels := expandLabels(d.Get("labels").(map[string]interface{}))
-	taints := expandTaints(d.Get("taint").(*schema.Set).List())
+		nodePool, err := findNodePoolByName(client, clusterName, poolName)
+		if err != nil {
+			return nil, err
+		}
 
-	autoscaling := expandAutoscalingSettings(d.Get("autoscaling").(*schema.Set).List())
+		d.SetId(nodePool.ID)
+		// Keeps a pool renamed by a surge replacement under its configured name
+		d.Set("name", poolName)
+	}
 
-	input := &symbiosis.NodePoolInput{
-		Name:         d.Get("name").(string),
-		ClusterName:  d.Get("cluster").(string),
-		NodeTypeName: d.Get("node_type").(string),
-		Quantity:     d.Get("quantity").(int),
-		Labels:       labels,
-		Taints:       taints,
-		Autoscaling:  autoscaling,
+	// Provider-side settings are not stored in the API, start them at their defaults
+	d.Set("replacement_strategy", "recreate")
+	d.Set("max_surge", 0)
+	d.Set("adopt_existing", false)
+	return []*schema.ResourceData{d}, nil
+}
+
+func findNodePoolByName(client *symbiosis.Client, clusterName string, poolName string) (*symbiosis.NodePool, error) {
+	nodePool, err := lookupNodePoolByName(client, clusterName, poolName)
+	if err != nil {
+		return nil, err
+	}
+	if nodePool == nil {
+		return nil, fmt.Errorf("Node pool %s not found in cluster %s", poolName, clusterName)
+	}
+
+	return nodePool, nil
+}
+
+// lookupNodePoolByName is like findNodePoolByName but returns nil when the
+// cluster has no pool with that name. A pool renamed by a surge replacement is
+// found by its configured name unless a pool with exactly that name exists.
+func lookupNodePoolByName(client *symbiosis.Client, clusterName string, poolName string) (*symbiosis.NodePool, error) {
+	cluster, err := client.Cluster.Describe(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("Error describing cluster %s: %w", clusterName, err)
+	}
+
+	var exact, surged []*symbiosis.NodePool
+	for _, pool := range cluster.NodePools {
+		if pool.Name == poolName {
+			exact = append(exact, pool)
+		} else if isSurgeNodePoolName(pool.Name, poolName) {
+			surged = append(surged, pool)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = surged
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("Cluster %s has more than one node pool named %s, use its ID instead", clusterName, poolName)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	return matches[0], nil
+}
+
+// adoptExistingNodePool takes over a node pool that already exists with the
+// requested name, e.g. one left behind by an interrupted apply.
+func adoptExistingNodePool(client *symbiosis.Client, input *symbiosis.NodePoolInput) (*symbiosis.NodePool, error) {
+	nodePool, err := findNodePoolByName(client, input.ClusterName, input.Name)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot adopt existing node pool: %w", err)
+	}
+
+	if nodePool.NodeTypeName != input.NodeTypeName {
+		return nil, fmt.Errorf("Cannot adopt existing node pool %s: it has node type %s instead of %s", input.Name, nodePool.NodeTypeName, input.NodeTypeName)
+	}
+
+	log.Printf("[INFO] Adopting existing node pool %s (%s)", nodePool.Name, nodePool.ID)
+	return nodePool, nil
+}
+
+func resourceNodePoolCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	autoscaling := expandAutoscalingSettings(d.Get("autoscaling").([]interface{}))
+	if autoscaling.Enabled {
+		if autoscaling.MinSize > autoscaling.MaxSize {
+			return fmt.Errorf("autoscaling min_size (%d) must not be larger than max_size (%d)", autoscaling.MinSize, autoscaling.MaxSize)
+		}
+
+		// quantity is optional when autoscaling is enabled, but must fit the bounds when given
+		if quantity, ok := d.GetOk("quantity"); ok && (quantity.(int) < autoscaling.MinSize || quantity.(int) > autoscaling.MaxSize) {
+			return fmt.Errorf("quantity (%d) must lie between autoscaling min_size (%d) and max_size (%d)", quantity.(int), autoscaling.MinSize, autoscaling.MaxSize)
+		}
+
+		if current, ok := d.GetOk("current_quantity"); ok && (current.(int) < autoscaling.MinSize || current.(int) > autoscaling.MaxSize) {
+			return fmt.Errorf("node pool currently has %d nodes, which is outside autoscaling bounds [%d, %d]", current.(int), autoscaling.MinSize, autoscaling.MaxSize)
+		}
+	} else {
+		quantity, ok := d.GetOk("quantity")
+		if !ok || quantity.(int) < 1 {
+			return fmt.Errorf("Quantity must be at least 1 if autoscaling is disabled")
+		}
+	}
+
+	if d.Id() != "" && d.HasChange("node_type") {
+		if d.Get("replacement_strategy").(string) != "surge" {
+			return d.ForceNew("node_type")
+		}
+
+		// A surge replacement swaps in a new pool with its own ID and nodes
+		for _, key := range []string{"id", "nodes", "observed_quantity"} {
+			err := d.SetNewComputed(key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Scaling adds or removes nodes, which are only known after the apply
+	if d.Id() != "" && (d.HasChange("quantity") || d.HasChange("autoscaling")) {
+		for _, key := range []string{"nodes", "observed_quantity", "current_quantity"} {
+			err := d.SetNewComputed(key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	m := meta.(*providerMeta)
+	err := m.policy.checkNodePool(ctx, d, m)
+	if err != nil {
+		return err
+	}
+
+	warning, err := checkNodePoolQuota(ctx, d, m)
+	if err != nil {
+		return err
+	}
+	err = setQuotaWarning(d, warning)
+	if err != nil {
+		return err
+	}
+
+	if (d.Id() == "" || d.HasChange("node_type") || d.HasChange("quantity") || d.HasChange("autoscaling")) &&
+		d.NewValueKnown("node_type") && d.NewValueKnown("quantity") && d.NewValueKnown("autoscaling") {
+		cost, err := m.nodePoolMonthlyCost(ctx, d.Get("node_type").(string), d.Get("quantity").(int), autoscaling)
+		if err != nil {
+			// An estimate is not worth failing the plan for
+			log.Printf("[WARN] Cannot estimate cost of node pool: %s", err)
+		} else {
+			err = d.SetNew("estimated_monthly_cost", cost)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return validateNodePoolVersionSkew(ctx, d, m)
+}
+
+// suppressQuantityDiffWhenAutoscaling ignores quantity changes while the
+// autoscaler owns the size of an existing pool.
+func suppressQuantityDiffWhenAutoscaling(k, old, new string, d *schema.ResourceData) bool {
+	if d.Id() == "" {
+		return false
 	}
+	autoscaling := expandAutoscalingSettings(d.Get("autoscaling").([]interface{}))
+	return autoscaling.Enabled
+}
 
-	resp, err := client.NodePool.Create(input)
+func resourceNodePoolCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[DEBUG] Creating node pool with type %v for cluster %v", d.Get("node_type").(string), d.Id())
+	m := meta.(*providerMeta)
+	client, err := m.client(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	unlock, err := lockClusterForNodePool(ctx, m, client, d.Get("cluster").(string), d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return apiErrorDiags(err, nameNodePool)
+	}
+	defer unlock()
+	defer m.planned.remove(nodePoolQuotaKey(d.Get("cluster").(string), d.Get("name").(string)))
+
+	input := expandNodePoolInput(d)
+
+	var resp *symbiosis.NodePool
+	err = createIdempotently(ctx, m, d.Timeout(schema.TimeoutCreate), func(c *symbiosis.Client) (err error) {
+		resp, err = c.NodePool.Create(input)
+		return err
+	}, func(c *symbiosis.Client) (bool, error) {
+		existing, err := lookupNodePoolByName(c, input.ClusterName, input.Name)
+		if existing != nil {
+			resp = existing
+		}
+		return existing != nil, err
+	})
+	if isConflictError(err) && d.Get("adopt_existing").(bool) {
+		resp, err = adoptExistingNodePool(client, input)
+	}
+	if err != nil {
+		return apiErrorDiags(err, nameNodePool)
+	}
+
 	d.SetId(resp.ID)
 
-	return diags
+	quantity := input.Quantity
+	if input.Autoscaling.Enabled && quantity == 0 {
+		quantity = input.Autoscaling.MinSize
+	}
+
+	err = waitForNodePoolReady(ctx, m, client, resp.ID, quantity, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return apiErrorDiags(err, nameNodePool)
+	}
+
+	// Responses cached while the pool was created do not include it yet
+	m.cache.invalidateCluster(input.ClusterName)
+	return resourceNodePoolRead(ctx, d, meta)
 }
 
 func resourceNodePoolUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating node pool: %s", d.Id())
-	var diags diag.Diagnostics
-	client := meta.(*symbiosis.Client)
+	m := meta.(*providerMeta)
+	client, err := m.client(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-	autoscaling := expandAutoscalingSettings(d.Get("autoscaling").(*schema.Set).List())
+	autoscaling := expandAutoscalingSettings(d.Get("autoscaling").([]interface{}))
 
 	log.Printf("[DEBUG] Updating node pool: %v", autoscaling)
 
+	unlock, err := lockClusterForNodePool(ctx, m, client, d.Get("cluster").(string), d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return apiErrorDiags(err, nameNodePool)
+	}
+	defer unlock()
+	defer m.planned.remove(nodePoolQuotaKey(d.Get("cluster").(string), d.Get("name").(string)))
+
 	id := d.Id()
 	currentNodePool, err := client.NodePool.Describe(id)
 
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameNodePool)
+	}
+
+	if d.HasChange("node_type") {
+		nodePool, err := surgeReplaceNodePool(ctx, d, m, currentNodePool)
+		if err != nil {
+			return apiErrorDiags(err, nameNodePool)
+		}
+		d.SetId(nodePool.ID)
+		m.cache.invalidateCluster(d.Get("cluster").(string))
+		return resourceNodePoolRead(ctx, d, meta)
 	}
 
 	quantity := d.Get("quantity").(int)
@@ -188,48 +534,190 @@ func resourceNodePoolUpdate(ctx context.Context, d *schema.ResourceData, meta in
 		quantity = currentNodePool.DesiredQuantity
 	}
 
+	drain := expandDrainOptions(d.Get("drain").([]interface{}))
+	if drain != nil && quantity < currentNodePool.DesiredQuantity {
+		err = drainForScaleDown(ctx, client, currentNodePool, currentNodePool.DesiredQuantity-quantity, drain)
+		if err != nil {
+			return apiErrorDiags(err, nameNodePool)
+		}
+	}
+
 	input := &symbiosis.NodePoolUpdateInput{
 		Quantity:    quantity,
 		Autoscaling: autoscaling,
+		KubeVersion: d.Get("kube_version").(string),
+	}
+
+	if d.HasChange("kube_version") {
+		err = upgradeNodePool(ctx, d, m, currentNodePool, input)
+		if err != nil {
+			return apiErrorDiags(err, nameNodePool)
+		}
+		m.cache.invalidateCluster(d.Get("cluster").(string))
+		return resourceNodePoolRead(ctx, d, meta)
 	}
+
 	err = client.NodePool.Update(id, input)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameNodePool)
 	}
 
-	return diags
+	if !autoscaling.Enabled && quantity > currentNodePool.DesiredQuantity {
+		err = waitForNodePoolReady(ctx, m, client, id, quantity, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			return apiErrorDiags(err, nameNodePool)
+		}
+	}
+
+	// Responses cached during the update still show the old size
+	m.cache.invalidateCluster(d.Get("cluster").(string))
+	return resourceNodePoolRead(ctx, d, meta)
 }
 
 func resourceNodePoolDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Deleting node pool: %s", d.Id())
-	client := meta.(*symbiosis.Client)
-
-	err := client.NodePool.Delete(d.Id())
+	m := meta.(*providerMeta)
+	client, err := m.client(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	// Pools of a FAILED or upgrading cluster can be deleted, so only the lock is taken
+	unlock, err := m.lockCluster(ctx, d.Get("cluster").(string))
+	if err != nil {
+		return apiErrorDiags(err, nameNodePool)
+	}
+	defer unlock()
+
+	drain := expandDrainOptions(d.Get("drain").([]interface{}))
+	if drain != nil {
+		nodePool, err := client.NodePool.Describe(d.Id())
+		if err != nil && !isNotFoundError(err) {
+			return apiErrorDiags(err, nameNodePool)
+		}
+		if nodePool != nil {
+			err = drainNodes(ctx, client, nodePool.ClusterName, nodePool.Nodes, drain)
+			if err != nil {
+				return apiErrorDiags(err, nameNodePool)
+			}
+		}
+	}
+
+	// The node pool is already gone when its cluster was deleted first
+	err = client.NodePool.Delete(d.Id())
+	if isNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return apiErrorDiags(err, nameNodePool)
+	}
+
+	err = m.retry(ctx, d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		_, err := client.NodePool.Describe(d.Id())
+		if isNotFoundError(err) {
+			return nil
+		}
+		if err != nil {
+			return resource.NonRetryableError(fmt.Errorf("Error describing node pool: %w", err))
+		}
+		return resource.RetryableError(fmt.Errorf("expected node pool to get removed but it is still returned from api"))
+	})
+	if err != nil {
+		return apiErrorDiags(err, nameNodePool)
+	}
+
 	var diags diag.Diagnostics
 	return diags
 }
 
+// drainForScaleDown drains and removes the newest nodes of the pool so that the
+// following quantity update does not take down nodes with running workloads.
+// The API cannot remove a chosen node and shrink the pool in one call, so the
+// desired quantity is lowered right after each node is deleted to keep the API
+// from replacing it.
+func drainForScaleDown(ctx context.Context, client *symbiosis.Client, nodePool *symbiosis.NodePool, count int, drain *drainOptions) error {
+	if count > len(nodePool.Nodes) {
+		count = len(nodePool.Nodes)
+	}
+
+	nodes := make([]*symbiosis.Node, len(nodePool.Nodes))
+	copy(nodes, nodePool.Nodes)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].CreatedAt.After(nodes[j].CreatedAt)
+	})
+	nodes = nodes[:count]
+
+	err := drainNodes(ctx, client, nodePool.ClusterName, nodes, drain)
+	if err != nil {
+		return err
+	}
+
+	quantity := nodePool.DesiredQuantity
+	for _, node := range nodes {
+		log.Printf("[DEBUG] Deleting drained node %s of node pool %s", node.Name, nodePool.ID)
+		err := client.Node.Delete(node.Name)
+		if err != nil && !isNotFoundError(err) {
+			return err
+		}
+
+		quantity--
+		err = client.NodePool.Update(nodePool.ID, &symbiosis.NodePoolUpdateInput{
+			Quantity:    quantity,
+			Autoscaling: nodePool.Autoscaling,
+			KubeVersion: nodePool.KubeVersion,
+		})
+		if err != nil {
+			return fmt.Errorf("Error lowering node pool %s to %d nodes: %w", nodePool.ID, quantity, err)
+		}
+	}
+
+	return nil
+}
+
 func resourceNodePoolRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading node pool: %s", d.Id())
-	client := meta.(*symbiosis.Client)
-	nodePool, err := client.NodePool.Describe(d.Id())
-	if err != nil {
-		return diag.FromErr(err)
+	m := meta.(*providerMeta)
+	nodePool, err := m.cache.describeNodePool(ctx, m, d.Id())
+	if err != nil && !isNotFoundError(err) {
+		return apiErrorDiags(err, nameNodePool)
 	}
 	if nodePool != nil {
 
-		d.Set("name", nodePool.Name)
+		// A surge replacement carries a temporary name, the pool keeps its configured one
+		if !isSurgeNodePoolName(nodePool.Name, d.Get("name").(string)) {
+			d.Set("name", nodePool.Name)
+		}
 		d.Set("cluster", nodePool.ClusterName)
 		d.Set("node_type", nodePool.NodeTypeName)
-		d.Set("quantity", nodePool.DesiredQuantity)
+		d.Set("kube_version", nodePool.KubeVersion)
+		// The autoscaler owns the size, so quantity keeps its configured value
+		if !nodePool.Autoscaling.Enabled {
+			d.Set("quantity", nodePool.DesiredQuantity)
+		}
+		d.Set("current_quantity", nodePool.DesiredQuantity)
 		d.Set("labels", flattenLabels(nodePool.Labels))
-		d.Set("taints", flattenedTaints(nodePool.Taints))
-		d.Set("autoscaling", flattenAutoscalingSettings(nodePool.Autoscaling))
+		d.Set("taint", orderTaints(flattenTaints(nodePool.Taints), d.Get("taint").([]interface{})))
+		// Pools without autoscaling have no block, unless one is configured with enabled = false
+		if nodePool.Autoscaling.Enabled || len(d.Get("autoscaling").([]interface{})) > 0 {
+			d.Set("autoscaling", flattenAutoscalingSettings(nodePool.Autoscaling))
+		} else {
+			d.Set("autoscaling", nil)
+		}
+		d.Set("observed_quantity", len(nodePool.Nodes))
+		d.Set("nodes", flattenNodes(nodePool.Nodes))
+
+		cost, err := m.nodePoolMonthlyCost(ctx, nodePool.NodeTypeName, nodePool.DesiredQuantity, nodePool.Autoscaling)
+		if err != nil {
+			log.Printf("[WARN] Cannot estimate cost of node pool %s: %s", nodePool.Name, err)
+		} else {
+			d.Set("estimated_monthly_cost", cost)
+		}
+		// Keep the warning of the plan that created this pool until the next refresh
+		if !d.IsNewResource() {
+			d.Set("quota_warning", "")
+		}
 	} else {
+		log.Printf("[WARN] Node pool %s not found, removing from state", d.Id())
 		d.SetId("")
 	}
 
@@ -237,6 +725,24 @@ func resourceNodePoolRead(ctx context.Context, d *schema.ResourceData, meta inte
 	return diags
 }
 
+func expandNodePoolInput(d *schema.ResourceData) *symbiosis.NodePoolInput {
+	labels := expandLabels(d.Get("labels").(map[string]interface{}))
+	taints := expandTaints(d.Get("taint").([]interface{}))
+
+	autoscaling := expandAutoscalingSettings(d.Get("autoscaling").([]interface{}))
+
+	return &symbiosis.NodePoolInput{
+		Name:         d.Get("name").(string),
+		ClusterName:  d.Get("cluster").(string),
+		NodeTypeName: d.Get("node_type").(string),
+		Quantity:     d.Get("quantity").(int),
+		Labels:       labels,
+		Taints:       taints,
+		Autoscaling:  autoscaling,
+		KubeVersion:  d.Get("kube_version").(string),
+	}
+}
+
 func expandTaints(taints []interface{}) []symbiosis.NodeTaint {
 	convertedTaints := make([]symbiosis.NodeTaint, 0, len(taints))
 	for _, taint := range taints {
@@ -277,7 +783,7 @@ func flattenLabels(labels []*symbiosis.NodeLabel) map[string]interface{} {
 	return flattenedLabels
 }
 
-func flattenedTaints(input []*symbiosis.NodeTaint) []interface{} {
+func flattenTaints(input []*symbiosis.NodeTaint) []interface{} {
 	taints := make([]interface{}, 0)
 	if input == nil {
 		return taints
@@ -287,7 +793,7 @@ func flattenedTaints(input []*symbiosis.NodeTaint) []interface{} {
 		rawTaint := map[string]interface{}{
 			"key":    taint.Key,
 			"value":  taint.Value,
-			"effect": taint.Effect,
+			"effect": string(taint.Effect),
 		}
 
 		taints = append(taints, rawTaint)
@@ -296,6 +802,83 @@ func flattenedTaints(input []*symbiosis.NodeTaint) []interface{} {
 	return taints
 }
 
+func flattenNodes(input []*symbiosis.Node) []interface{} {
+	nodes := make([]interface{}, 0, len(input))
+
+	for _, node := range input {
+		createdAt := ""
+		if !node.CreatedAt.IsZero() {
+			createdAt = node.CreatedAt.Format(time.RFC3339)
+		}
+
+		nodes = append(nodes, map[string]interface{}{
+			"id":         node.ID,
+			"name":       node.Name,
+			"state":      node.State,
+			"private_ip": node.PrivateIPv4Address,
+			"public_ip":  node.PublicIPv4Address,
+			"created_at": createdAt,
+		})
+	}
+
+	return nodes
+}
+
+// orderTaints sorts taints read from the API in the order of the previously
+// known taints, so reordering by the API does not show up as a diff.
+func orderTaints(taints []interface{}, previous []interface{}) []interface{} {
+	position := make(map[string]int, len(previous))
+	for i, taint := range previous {
+		t := taint.(map[string]interface{})
+		position[t["key"].(string)+":"+t["effect"].(string)] = i
+	}
+
+	index := func(taint interface{}) int {
+		t := taint.(map[string]interface{})
+		if i, ok := position[t["key"].(string)+":"+t["effect"].(string)]; ok {
+			return i
+		}
+		return len(previous)
+	}
+
+	sort.SliceStable(taints, func(i, j int) bool {
+		return index(taints[i]) < index(taints[j])
+	})
+
+	return taints
+}
+
+// suppressTaintReorder ignores taint changes that only reorder the same
+// taints, e.g. the set order kept in state written before taint became a list.
+func suppressTaintReorder(k, old, new string, d *schema.ResourceData) bool {
+	o, n := d.GetChange("taint")
+	return taintsEqual(o.([]interface{}), n.([]interface{}))
+}
+
+// taintsEqual reports whether a and b hold the same taints in any order.
+func taintsEqual(a []interface{}, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[string]int, len(a))
+	taintID := func(taint interface{}) string {
+		t, _ := taint.(map[string]interface{})
+		return fmt.Sprintf("%v=%v:%v", t["key"], t["value"], t["effect"])
+	}
+	for _, taint := range a {
+		counts[taintID(taint)]++
+	}
+	for _, taint := range b {
+		id := taintID(taint)
+		if counts[id] == 0 {
+			return false
+		}
+		counts[id]--
+	}
+	return true
+}
+
 func flattenAutoscalingSettings(input symbiosis.AutoscalingSettings) []interface{} {
 	settings := make([]interface{}, 0)
 
@@ -0,0 +1,122 @@
+package symbiosis
+
+import (
+	"context"
+	"log"
+
+	"github.com/symbiosis-cloud/symbiosis-go"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceNodePoolV0 is the node pool schema before autoscaling and taint
+// became lists.
+func resourceNodePoolV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"cluster": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"node_type": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"quantity": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"labels": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"taint": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"effect": {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: validation.StringInSlice([]string{
+								string(symbiosis.EFFECT_NO_SCHEDULE),
+								string(symbiosis.EFFECT_NO_EXECUTE),
+								string(symbiosis.EFFECT_PREFER_NO_SCHEDULE),
+							}, false),
+						},
+					},
+				},
+			},
+			"autoscaling": {
+				Type:     schema.TypeSet,
+				MaxItems: 1,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Required: true,
+						},
+						"min_size": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"max_size": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// resourceNodePoolStateUpgradeV0 turns the autoscaling and taint sets into
+// lists. Both are stored as JSON arrays, so only missing or empty values need
+// normalizing.
+func resourceNodePoolStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	log.Printf("[DEBUG] Upgrading node pool state from version 0: %s", rawState["id"])
+
+	for _, key := range []string{"autoscaling", "taint"} {
+		if _, ok := rawState[key].([]interface{}); !ok {
+			rawState[key] = []interface{}{}
+		}
+	}
+
+	// Older versions wrote to a non-existent "taints" attribute
+	delete(rawState, "taints")
+
+	for _, taint := range rawState["taint"].([]interface{}) {
+		if t, ok := taint.(map[string]interface{}); ok && t["value"] == nil {
+			t["value"] = ""
+		}
+	}
+
+	return rawState, nil
+}
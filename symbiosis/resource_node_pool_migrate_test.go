@@ -0,0 +1,74 @@
+package symbiosis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestResourceNodePoolStateUpgradeV0(t *testing.T) {
+	cases := []struct {
+		name  string
+		state map[string]interface{}
+		want  map[string]interface{}
+	}{
+		{
+			name:  "missing sets",
+			state: map[string]interface{}{"id": "pool-1"},
+			want: map[string]interface{}{
+				"id":          "pool-1",
+				"autoscaling": []interface{}{},
+				"taint":       []interface{}{},
+			},
+		},
+		{
+			name: "null sets and stray taints",
+			state: map[string]interface{}{
+				"id":          "pool-1",
+				"autoscaling": nil,
+				"taint":       nil,
+				"taints":      []interface{}{},
+			},
+			want: map[string]interface{}{
+				"id":          "pool-1",
+				"autoscaling": []interface{}{},
+				"taint":       []interface{}{},
+			},
+		},
+		{
+			name: "sets kept in order with missing taint values",
+			state: map[string]interface{}{
+				"id": "pool-1",
+				"autoscaling": []interface{}{
+					map[string]interface{}{"enabled": true, "min_size": float64(1), "max_size": float64(3)},
+				},
+				"taint": []interface{}{
+					map[string]interface{}{"key": "spot", "effect": "NoExecute"},
+					map[string]interface{}{"key": "dedicated", "value": "web", "effect": "NoSchedule"},
+				},
+			},
+			want: map[string]interface{}{
+				"id": "pool-1",
+				"autoscaling": []interface{}{
+					map[string]interface{}{"enabled": true, "min_size": float64(1), "max_size": float64(3)},
+				},
+				"taint": []interface{}{
+					map[string]interface{}{"key": "spot", "value": "", "effect": "NoExecute"},
+					map[string]interface{}{"key": "dedicated", "value": "web", "effect": "NoSchedule"},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := resourceNodePoolStateUpgradeV0(context.Background(), c.state, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package symbiosis
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+func testNodePoolState(taints ...map[string]string) *terraform.InstanceState {
+	attributes := map[string]string{
+		"id":                   "pool-1",
+		"name":                 "web",
+		"cluster":              "prod",
+		"node_type":            "general-1",
+		"quantity":             "3",
+		"current_quantity":     "3",
+		"observed_quantity":    "3",
+		"adopt_existing":       "false",
+		"replacement_strategy": "recreate",
+		"max_surge":            "0",
+		"nodes.#":              "0",
+		"taint.#":              strconv.Itoa(len(taints)),
+	}
+	for i, taint := range taints {
+		prefix := "taint." + strconv.Itoa(i) + "."
+		for k, v := range taint {
+			attributes[prefix+k] = v
+		}
+	}
+
+	return &terraform.InstanceState{ID: "pool-1", Attributes: attributes}
+}
+
+func testNodePoolConfig(taints ...map[string]interface{}) *terraform.ResourceConfig {
+	raw := map[string]interface{}{
+		"name":      "web",
+		"cluster":   "prod",
+		"node_type": "general-1",
+		"quantity":  3,
+	}
+	if len(taints) > 0 {
+		blocks := make([]interface{}, 0, len(taints))
+		for _, taint := range taints {
+			blocks = append(blocks, taint)
+		}
+		raw["taint"] = blocks
+	}
+	return terraform.NewResourceConfigRaw(raw)
+}
+
+func TestResourceNodePoolDiffTaint(t *testing.T) {
+	m := testProviderMeta()
+	m.cache.clusters["prod"] = &symbiosis.Cluster{Name: "prod", KubeVersion: "1.24.3"}
+	m.cache.nodeTypes = []*symbiosis.NodeType{{Name: "general-1", Vcpu: 2}}
+
+	state := testNodePoolState(
+		map[string]string{"key": "dedicated", "value": "web", "effect": "NoSchedule"},
+		map[string]string{"key": "spot", "value": "", "effect": "NoExecute"},
+	)
+
+	cases := []struct {
+		name        string
+		config      *terraform.ResourceConfig
+		requiresNew bool
+		empty       bool
+	}{
+		{
+			name: "value changed",
+			config: testNodePoolConfig(
+				map[string]interface{}{"key": "dedicated", "value": "changed", "effect": "NoSchedule"},
+				map[string]interface{}{"key": "spot", "effect": "NoExecute"},
+			),
+			requiresNew: true,
+		},
+		{
+			name: "effect changed",
+			config: testNodePoolConfig(
+				map[string]interface{}{"key": "dedicated", "value": "web", "effect": "NoExecute"},
+				map[string]interface{}{"key": "spot", "effect": "NoExecute"},
+			),
+			requiresNew: true,
+		},
+		{
+			name: "reordered",
+			config: testNodePoolConfig(
+				map[string]interface{}{"key": "spot", "effect": "NoExecute"},
+				map[string]interface{}{"key": "dedicated", "value": "web", "effect": "NoSchedule"},
+			),
+			empty: true,
+		},
+		{
+			name: "unchanged",
+			config: testNodePoolConfig(
+				map[string]interface{}{"key": "dedicated", "value": "web", "effect": "NoSchedule"},
+				map[string]interface{}{"key": "spot", "effect": "NoExecute"},
+			),
+			empty: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			diff, err := ResourceNodePool().Diff(context.Background(), state, c.config, m)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if c.empty {
+				if diff != nil && !diff.Empty() {
+					t.Fatalf("expected no diff, got %#v", diff.Attributes)
+				}
+				return
+			}
+			if diff == nil || diff.RequiresNew() != c.requiresNew {
+				t.Fatalf("expected requiresNew %t, got %#v", c.requiresNew, diff)
+			}
+		})
+	}
+}
+
+func TestOrderTaints(t *testing.T) {
+	taint := func(key, effect string) interface{} {
+		return map[string]interface{}{"key": key, "value": "", "effect": effect}
+	}
+
+	cases := []struct {
+		name     string
+		taints   []interface{}
+		previous []interface{}
+		want     []interface{}
+	}{
+		{
+			name:     "previous order",
+			taints:   []interface{}{taint("b", "NoSchedule"), taint("a", "NoSchedule")},
+			previous: []interface{}{taint("a", "NoSchedule"), taint("b", "NoSchedule")},
+			want:     []interface{}{taint("a", "NoSchedule"), taint("b", "NoSchedule")},
+		},
+		{
+			name:     "new taints last in API order",
+			taints:   []interface{}{taint("c", "NoSchedule"), taint("b", "NoExecute"), taint("a", "NoSchedule")},
+			previous: []interface{}{taint("a", "NoSchedule")},
+			want:     []interface{}{taint("a", "NoSchedule"), taint("c", "NoSchedule"), taint("b", "NoExecute")},
+		},
+		{
+			name:     "same key with another effect",
+			taints:   []interface{}{taint("a", "NoExecute"), taint("a", "NoSchedule")},
+			previous: []interface{}{taint("a", "NoSchedule"), taint("a", "NoExecute")},
+			want:     []interface{}{taint("a", "NoSchedule"), taint("a", "NoExecute")},
+		},
+		{
+			name:   "no previous taints",
+			taints: []interface{}{taint("b", "NoSchedule"), taint("a", "NoSchedule")},
+			want:   []interface{}{taint("b", "NoSchedule"), taint("a", "NoSchedule")},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := orderTaints(c.taints, c.previous)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/symbiosis-cloud/symbiosis-go"
 	"log"
+	"time"
 )
 
 func ResourceTeamMember() *schema.Resource {
@@ -21,6 +22,13 @@ func ResourceTeamMember() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceTeamMemberCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		SchemaVersion: 0,
 		Schema: map[string]*schema.Schema{
 			"email": {
 				Type:        schema.TypeString,
@@ -43,13 +51,20 @@ func ResourceTeamMember() *schema.Resource {
 	}
 }
 
+func resourceTeamMemberCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	return meta.(*providerMeta).policy.checkTeamMember(d)
+}
+
 func resourceTeamMemberCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*symbiosis.Client)
+	client, err := meta.(*providerMeta).client(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	email := d.Get("email").(string)
 
-	_, err := client.Team.InviteMembers([]string{email}, symbiosis.UserRole(d.Get("role").(string)))
+	_, err = client.Team.InviteMembers([]string{email}, symbiosis.UserRole(d.Get("role").(string)))
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameTeamMember)
 	}
 
 	d.SetId(email)
@@ -58,13 +73,16 @@ func resourceTeamMemberCreate(ctx context.Context, d *schema.ResourceData, meta
 }
 
 func resourceTeamMemberUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*symbiosis.Client)
+	client, err := meta.(*providerMeta).client(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	if d.HasChange("role") {
 
 		err := client.Team.ChangeRole(d.Id(), symbiosis.UserRole(d.Get("role").(string)))
 
 		if err != nil {
-			return diag.FromErr(err)
+			return apiErrorDiags(err, nameTeamMember)
 		}
 	}
 	var diags diag.Diagnostics
@@ -72,24 +90,30 @@ func resourceTeamMemberUpdate(ctx context.Context, d *schema.ResourceData, meta
 }
 
 func resourceTeamMemberDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*symbiosis.Client)
-
-	err := client.Team.DeleteMember(d.Id())
+	client, err := meta.(*providerMeta).client(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	err = client.Team.DeleteMember(d.Id())
+	if err != nil {
+		return apiErrorDiags(err, nameTeamMember)
+	}
+
 	var diags diag.Diagnostics
 	return diags
 }
 
 func resourceTeamMemberRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*symbiosis.Client)
+	client, err := meta.(*providerMeta).client(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	member, err := client.Team.GetMemberByEmail(d.Id())
 	var diags diag.Diagnostics
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameTeamMember)
 	}
 	if member != nil {
 		log.Printf("[DEBUG] member invite: %+v", member)
@@ -101,7 +125,7 @@ func resourceTeamMemberRead(ctx context.Context, d *schema.ResourceData, meta in
 
 	invitation, err := client.Team.GetInvitationByEmail(d.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiags(err, nameTeamMember)
 	}
 	if invitation != nil {
 		log.Printf("[DEBUG] invite: %+v", invitation)
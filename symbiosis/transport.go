@@ -0,0 +1,170 @@
+package symbiosis
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
+	"github.com/symbiosis-cloud/symbiosis-go"
+)
+
+const redacted = "REDACTED"
+
+var (
+	redactedHeaders = []string{"X-Auth-ApiKey", "Authorization", "Cookie", "Set-Cookie"}
+
+	pemBlockPattern = regexp.MustCompile(`-----BEGIN [A-Z ]+-----[\s\S]*?-----END [A-Z ]+-----`)
+
+	// JSON string fields carrying credentials, e.g. serviceAccountToken, privateKeyPem, kubeConfig or apiKey.
+	// A bare "key" is left alone as it names node labels and taints.
+	secretFieldPattern = regexp.MustCompile(`(?i)("(?:[a-z]*(?:token|secret|password|pem|kubeconfig)[a-z]*|[a-z]+key)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// loggingTransport logs every API request and response with credentials
+// redacted from headers and bodies.
+type loggingTransport struct {
+	next http.RoundTripper
+	logf func(format string, v ...interface{})
+}
+
+// httpDebugLogger returns where API traffic is logged, or nil when it is not.
+// Terraform drops plugin logs below its TF_LOG level, so SYMBIOSIS_HTTP_DEBUG
+// names a file the traffic is appended to regardless of TF_LOG.
+func httpDebugLogger() func(format string, v ...interface{}) {
+	if path := os.Getenv("SYMBIOSIS_HTTP_DEBUG"); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err == nil {
+			return log.New(f, "", log.LstdFlags).Printf
+		}
+		log.Printf("[WARN] Cannot open SYMBIOSIS_HTTP_DEBUG file %s: %s", path, err)
+	}
+	if logging.IsDebugOrHigher() {
+		return log.Printf
+	}
+	return nil
+}
+
+// withHTTPLogging installs loggingTransport on the API client when HTTP
+// debugging is enabled.
+func withHTTPLogging() symbiosis.ClientOption {
+	return func(c *resty.Client) {
+		if logf := httpDebugLogger(); logf != nil {
+			c.SetTransport(newLoggingTransport(c.GetClient().Transport, logf))
+		}
+	}
+}
+
+// contextTransport additionally cancels every request when ctx is done.
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+// RoundTrip cancels the request when either its own context, which carries
+// resty's and http.Client's timeouts, or the operation context is done.
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-t.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		case <-stop:
+		}
+	}()
+	release := func() {
+		close(stop)
+		cancel()
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The body is read after RoundTrip returns, keep the context alive until then
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// withContext makes the API client send its requests through transport bound to ctx.
+func withContext(ctx context.Context, transport http.RoundTripper) symbiosis.ClientOption {
+	return func(c *resty.Client) {
+		c.SetTransport(&contextTransport{ctx: ctx, next: transport})
+	}
+}
+
+func newLoggingTransport(next http.RoundTripper, logf func(format string, v ...interface{})) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &loggingTransport{next: next, logf: logf}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = body
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	t.logf("[DEBUG] Symbiosis API request: %s %s\nHeaders: %s\nBody: %s", req.Method, req.URL, redactHeaders(req.Header), redactBody(reqBody))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		t.logf("[DEBUG] Symbiosis API request failed: %s %s after %s: %s", req.Method, req.URL, duration, err)
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	t.logf("[DEBUG] Symbiosis API response: %s %s -> %s in %s (request id: %s)\nBody: %s", req.Method, req.URL, resp.Status, duration, requestID(resp), redactBody(respBody))
+
+	return resp, nil
+}
+
+func requestID(resp *http.Response) string {
+	for _, header := range []string{"X-Request-Id", "X-Amzn-Requestid", "X-Correlation-Id"} {
+		if id := resp.Header.Get(header); id != "" {
+			return id
+		}
+	}
+	return "-"
+}
+
+func redactHeaders(headers http.Header) http.Header {
+	clone := headers.Clone()
+	for _, header := range redactedHeaders {
+		if clone.Get(header) != "" {
+			clone.Set(header, redacted)
+		}
+	}
+	return clone
+}
+
+func redactBody(body []byte) string {
+	s := pemBlockPattern.ReplaceAllString(string(body), redacted)
+	return secretFieldPattern.ReplaceAllString(s, `$1"`+redacted+`"`)
+}
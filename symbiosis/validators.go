@@ -0,0 +1,20 @@
+package symbiosis
+
+import (
+	"fmt"
+	"time"
+)
+
+// validateDuration checks that the value is a valid Go duration string, e.g. "5m".
+func validateDuration(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+
+	if _, err := time.ParseDuration(v); err != nil {
+		return nil, []error{fmt.Errorf("expected %s to be a duration such as \"5m\", got %q", k, v)}
+	}
+
+	return nil, nil
+}
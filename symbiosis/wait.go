@@ -0,0 +1,38 @@
+package symbiosis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const defaultPollInterval = 5 * time.Second
+
+// retry calls f until it succeeds, returns a non-retryable error or timeout
+// expires, waiting the provider's poll interval between attempts. It returns
+// as soon as ctx is cancelled.
+func (m *providerMeta) retry(ctx context.Context, timeout time.Duration, f resource.RetryFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		rerr := f()
+		if rerr == nil {
+			return nil
+		}
+		if !rerr.Retryable {
+			return rerr.Err
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return &resource.TimeoutError{LastError: rerr.Err, Timeout: timeout}
+			}
+			return fmt.Errorf("%s: %s", ctx.Err(), rerr.Err)
+		case <-time.After(m.pollInterval):
+		}
+	}
+}